@@ -0,0 +1,99 @@
+package main
+
+import "time"
+
+const (
+	attentionTile  = 16 // side in cells of the squares activity is scored over
+	attentionSpan  = 3  // how many tiles across the camera frames
+	attentionYoung = 8  // generations a live cell counts as activity for
+)
+
+// attention is the camera mode for unattended displays: every so often it
+// finds the most active part of the board and the camera drifts over to it.
+// A cell is active while it is newly born, or dead with its trail still
+// fading, so still lifes and empty space score nothing. A nil attention is
+// off.
+type attention struct {
+	every  time.Duration
+	next   time.Time
+	target camera
+	ok     bool // whether there has been anything to look at yet
+}
+
+func newAttention(every time.Duration) *attention {
+	if every <= 0 {
+		return nil
+	}
+	return &attention{every: every}
+}
+
+// update picks a new target if it's time to, and returns where the camera
+// should head for, or false if nothing has been active yet.
+func (a *attention) update(meta *channels, now time.Time) (camera, bool) {
+	if now.Before(a.next) {
+		return a.target, a.ok
+	}
+	a.next = now.Add(a.every)
+	if x, y, ok := mostActive(meta); ok {
+		a.target, a.ok = attentionCamera(x, y), true
+	}
+	return a.target, a.ok
+}
+
+// mostActive returns the middle, in cells, of the attentionSpan tiles across
+// with the most activity, or false if nothing is active.
+func mostActive(meta *channels) (x, y int, ok bool) {
+	tilesX := (columns + attentionTile - 1) / attentionTile
+	tilesY := (rows + attentionTile - 1) / attentionTile
+	scores := make([][]float64, tilesX)
+	for tx := range scores {
+		scores[tx] = make([]float64, tilesY)
+	}
+	for cx := 0; cx < columns; cx++ {
+		for cy := 0; cy < rows; cy++ {
+			i := meta.index(cx, cy)
+			activity := float64(meta.heat[i])
+			if age := meta.age[i]; age > 0 {
+				activity = 0
+				if age <= attentionYoung {
+					activity = 1
+				}
+			}
+			scores[cx/attentionTile][cy/attentionTile] += activity
+		}
+	}
+
+	best := 0.0
+	for tx := range scores {
+		for ty := range scores[tx] {
+			// The middle tile counts twice, so the busiest tile ends up
+			// in the middle rather than at an edge.
+			score := scores[tx][ty]
+			for dx := -attentionSpan / 2; dx <= attentionSpan/2; dx++ {
+				for dy := -attentionSpan / 2; dy <= attentionSpan/2; dy++ {
+					if nx, ny := tx+dx, ty+dy; nx >= 0 && nx < tilesX && ny >= 0 && ny < tilesY {
+						score += scores[nx][ny]
+					}
+				}
+			}
+			if score > best {
+				best, ok = score, true
+				x, y = tx*attentionTile+attentionTile/2, ty*attentionTile+attentionTile/2
+			}
+		}
+	}
+	return x, y, ok
+}
+
+// attentionCamera frames attentionSpan tiles across around the cell x, y.
+func attentionCamera(x, y int) camera {
+	zoom := float32(columns) / (attentionSpan * attentionTile)
+	if zoom < 1 {
+		zoom = 1
+	}
+	return camera{
+		X:    (float32(x)+0.5)/float32(columns)*2 - 1,
+		Y:    (float32(y)+0.5)/float32(rows)*2 - 1,
+		Zoom: zoom,
+	}
+}
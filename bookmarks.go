@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/jake-shasteen/golang-gl-conway-life/input"
+	"image"
+)
+
+// bookmarkThumbnailSize is the width and height in pixels of the picture kept
+// with each bookmark.
+const bookmarkThumbnailSize = 128
+
+// bookmark is a generation marked with M, kept the same way as the
+// generations Rewind steps back through, along with a picture of it.
+type bookmark struct {
+	generation int
+	population int
+	state      []uint8
+	thumbnail  image.Image
+	path       string // where the thumbnail was saved, if it was
+}
+
+// bookmarks holds every bookmark in the order they were made, and is also the
+// menu for jumping back to one, opened with '. Like the picker, the menu
+// draws itself in the window title.
+type bookmarks struct {
+	marks    []bookmark
+	open     bool
+	selected int
+}
+
+// add bookmarks the board as it is at generation and returns the bookmark.
+func (m *bookmarks) add(b *board, generation int) *bookmark {
+	m.marks = append(m.marks, bookmark{
+		generation: generation,
+		population: b.population(),
+		state:      b.Save(nil),
+		thumbnail:  b.Thumbnail(bookmarkThumbnailSize, bookmarkThumbnailSize),
+	})
+	return &m.marks[len(m.marks)-1]
+}
+
+// handle applies one key and reports whether the menu is still open and
+// whether the board should jump to the selected bookmark.
+func (m *bookmarks) handle(t input.Text) (open, jump bool) {
+	switch t.Key {
+	case glfw.KeyEscape:
+		m.open = false
+	case glfw.KeyEnter, glfw.KeyKPEnter:
+		m.open = false
+		jump = true
+	case glfw.KeyDown, glfw.KeyTab:
+		m.selected = (m.selected + 1) % len(m.marks)
+	case glfw.KeyUp:
+		m.selected = (m.selected + len(m.marks) - 1) % len(m.marks)
+	}
+	return m.open, jump
+}
+
+// jump puts the selected bookmark back on the board and returns its
+// generation.
+func (m *bookmarks) jump(b *board) int {
+	mark := m.marks[m.selected]
+	b.Load(mark.state)
+	return mark.generation
+}
+
+// title describes the menu's state for the window title.
+func (m *bookmarks) title() string {
+	mark := m.marks[m.selected]
+	return fmt.Sprintf(tr("Jump to generation %v, population %v  (%v of %v, Up/Down to choose, Enter to jump)"),
+		mark.generation, mark.population, m.selected+1, len(m.marks))
+}
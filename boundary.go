@@ -0,0 +1,103 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+)
+
+// boundaryMode decides what a cell on the edge of the board sees past it. It
+// is a life.Boundary with the flag and key handling the game needs.
+type boundaryMode life.Boundary
+
+const (
+	boundaryWrap   = boundaryMode(life.BoundaryWrap)
+	boundaryDead   = boundaryMode(life.BoundaryDead)
+	boundaryMirror = boundaryMode(life.BoundaryMirror)
+)
+
+// boundary is the mode liveNeighbors uses. Change it while holding the
+// simulation lock.
+var boundary = boundaryWrap
+
+func (b boundaryMode) String() string {
+	return life.Boundary(b).String()
+}
+
+// Set parses a mode name, so a boundaryMode can be used as a flag.
+func (b *boundaryMode) Set(name string) error {
+	mode, err := life.ParseBoundary(name)
+	if err != nil {
+		return err
+	}
+	*b = boundaryMode(mode)
+	return nil
+}
+
+func (b boundaryMode) next() boundaryMode {
+	return (b + 1) % 3
+}
+
+func (b boundaryMode) resolve(x, y, columns, rows int) (int, int, bool) {
+	return life.Boundary(b).Resolve(x, y, columns, rows)
+}
+
+// boundaryInset keeps the markers clear of the edge of the window, since the
+// vertex shader scales the board up slightly.
+const boundaryInset = 0.97
+
+// boundaryMarker is the geometry drawn around the board for one mode.
+type boundaryMarker struct {
+	drawable uint32
+	vertices int32
+	color    [4]float32
+}
+
+// makeBoundaryMarkers builds the edge decoration for each mode: outward arrows
+// for wrap, hatching for dead walls and double lines for mirrors.
+func makeBoundaryMarkers() map[boundaryMode]boundaryMarker {
+	const e = boundaryInset
+
+	var arrows, hatching, mirror []float32
+	line := func(points *[]float32, x1, y1, x2, y2 float32) {
+		*points = append(*points, x1, y1, 0, x2, y2, 0)
+	}
+
+	// An arrow at the middle of each edge pointing off the board, where the
+	// pattern comes back in on the opposite side.
+	for _, s := range []float32{-1, 1} {
+		line(&arrows, s*(e-0.1), 0, s*e, 0)
+		line(&arrows, s*e, 0, s*(e-0.04), 0.04)
+		line(&arrows, s*e, 0, s*(e-0.04), -0.04)
+		line(&arrows, 0, s*(e-0.1), 0, s*e)
+		line(&arrows, 0, s*e, 0.04, s*(e-0.04))
+		line(&arrows, 0, s*e, -0.04, s*(e-0.04))
+	}
+
+	for i := float32(-e); i < e; i += 0.05 {
+		line(&hatching, i, -e, i+0.03, -e+0.03)
+		line(&hatching, i, e, i+0.03, e-0.03)
+		line(&hatching, -e, i, -e+0.03, i+0.03)
+		line(&hatching, e, i, e-0.03, i+0.03)
+	}
+
+	for _, inset := range []float32{e, e - 0.015} {
+		line(&mirror, -inset, -inset, inset, -inset)
+		line(&mirror, inset, -inset, inset, inset)
+		line(&mirror, inset, inset, -inset, inset)
+		line(&mirror, -inset, inset, -inset, -inset)
+	}
+
+	return map[boundaryMode]boundaryMarker{
+		boundaryWrap:   {makeVao(arrows), int32(len(arrows) / 3), [4]float32{0.3, 0.6, 1, 1}},
+		boundaryDead:   {makeVao(hatching), int32(len(hatching) / 3), [4]float32{0.9, 0.3, 0.3, 1}},
+		boundaryMirror: {makeVao(mirror), int32(len(mirror) / 3), [4]float32{0.8, 0.8, 0.9, 1}},
+	}
+}
+
+// draw draws the marker in its color using the overlay uniform.
+func (m boundaryMarker) draw(overlayLocation int32) {
+	gl.Uniform4f(overlayLocation, m.color[0], m.color[1], m.color[2], m.color[3])
+	gl.BindVertexArray(m.drawable)
+	gl.DrawArrays(gl.LINES, 0, m.vertices)
+	gl.Uniform4f(overlayLocation, 0, 0, 0, 0)
+}
@@ -0,0 +1,82 @@
+package main
+
+import "math"
+
+// camera is the point of the board at the middle of the window, in the same
+// -1 to 1 coordinates as the cells, and how far the board is zoomed in.
+type camera struct {
+	X    float32 `json:"x"`
+	Y    float32 `json:"y"`
+	Zoom float32 `json:"zoom"`
+}
+
+// view is where the camera is now. Change it while holding the simulation
+// lock.
+var view = camera{Zoom: 1}
+
+// projection returns the scale, as passed to the u_projection uniform, that
+// fits the part of the board a window of the given size shows into it without
+// stretching, so cells stay square however the window is resized.
+func projection(windowWidth, windowHeight int) (float32, float32) {
+	if windowWidth <= 0 || windowHeight <= 0 {
+		return 1, 1
+	}
+	shown := float32(columns) / viewScale / float32(rows)
+	window := float32(windowWidth) / float32(windowHeight)
+	if window > shown {
+		return shown / window, 1
+	}
+	return 1, window / shown
+}
+
+// toBoard maps a point in the window, in -1 to 1 coordinates, back to the
+// board.
+func (c camera) toBoard(x, y float64) (float64, float64) {
+	return x/float64(c.Zoom) + float64(c.X), y/float64(c.Zoom) + float64(c.Y)
+}
+
+// fitMargin is how much room fit leaves around the live cells, as a fraction
+// of their size, and fitMinCells how few cells across it zooms in to, so a
+// lone still life isn't blown up to fill the window.
+const (
+	fitMargin   = 0.1
+	fitMinCells = 16
+)
+
+// fit returns the camera that frames every live cell, or c if there are
+// none. It never zooms out past the whole board.
+func (c camera) fit(alive [][]bool) camera {
+	minX, minY, maxX, maxY, ok := boundingBox(alive)
+	if !ok {
+		return c
+	}
+
+	// The box in the board's -1 to 1 coordinates, at least fitMinCells
+	// across.
+	toBoard := func(i, n int) float32 { return float32(i)/float32(n)*2 - 1 }
+	x0, x1 := toBoard(minX, columns), toBoard(maxX+1, columns)
+	y0, y1 := toBoard(minY, rows), toBoard(maxY+1, rows)
+	size := x1 - x0
+	if y1-y0 > size {
+		size = y1 - y0
+	}
+	if least := float32(fitMinCells) * 2 / float32(columns); size < least {
+		size = least
+	}
+
+	zoom := 2 / (size * (1 + 2*fitMargin))
+	if zoom < 1 {
+		zoom = 1
+	}
+	return camera{X: (x0 + x1) / 2, Y: (y0 + y1) / 2, Zoom: zoom}
+}
+
+// toward returns c moved the fraction t of the way to target, zooming by the
+// same fraction of the ratio between them so the speed looks even.
+func (c camera) toward(target camera, t float64) camera {
+	return camera{
+		X:    c.X + (target.X-c.X)*float32(t),
+		Y:    c.Y + (target.Y-c.Y)*float32(t),
+		Zoom: c.Zoom * float32(math.Pow(float64(target.Zoom/c.Zoom), t)),
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// changeQueueSize is how many deltas a subscriber may fall behind by before
+// it is dropped.
+const changeQueueSize = 64
+
+// delta lists the cells that changed since the previous one, as x, y pairs.
+type delta struct {
+	Generation int      `json:"generation"`
+	Births     [][2]int `json:"births"`
+	Deaths     [][2]int `json:"deaths"`
+}
+
+// changeFeed streams the births and deaths of each generation to its
+// subscribers, so they can follow the board without scanning it. A nil
+// changeFeed does nothing.
+type changeFeed struct {
+	mu          sync.Mutex
+	before      [][]bool
+	subscribers map[chan delta]bool
+}
+
+// newChangeFeed returns a feed served as newline-delimited JSON on addr, or
+// nil if addr is empty.
+func newChangeFeed(addr string) *changeFeed {
+	if addr == "" {
+		return nil
+	}
+
+	f := &changeFeed{subscribers: make(map[chan delta]bool)}
+	go func() {
+		log.Println("streaming changes on", addr)
+		log.Println(http.ListenAndServe(addr, f))
+	}()
+	return f
+}
+
+// observe publishes every cell that has changed since the last call, which
+// includes edits and injections as well as the step itself.
+func (f *changeFeed) observe(cells [][]*cell, generation int) {
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	d := delta{Generation: generation}
+	for x := range cells {
+		for y, c := range cells[x] {
+			was := f.before != nil && f.before[x][y]
+			switch {
+			case c.alive() && !was:
+				d.Births = append(d.Births, [2]int{x, y})
+			case !c.alive() && was:
+				d.Deaths = append(d.Deaths, [2]int{x, y})
+			}
+		}
+	}
+	f.before = snapshot(cells)
+
+	for s := range f.subscribers {
+		select {
+		case s <- d:
+		default:
+			// A subscriber that has fallen behind can't make sense of later
+			// deltas, so it is cut off rather than skipped ahead.
+			delete(f.subscribers, s)
+			close(s)
+		}
+	}
+}
+
+// subscribe returns a channel of deltas starting with one that brings every
+// live cell to life, so the subscriber starts from the current board.
+func (f *changeFeed) subscribe() chan delta {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var first delta
+	for x := range f.before {
+		for y, alive := range f.before[x] {
+			if alive {
+				first.Births = append(first.Births, [2]int{x, y})
+			}
+		}
+	}
+
+	s := make(chan delta, changeQueueSize)
+	s <- first
+	f.subscribers[s] = true
+	return s
+}
+
+func (f *changeFeed) unsubscribe(s chan delta) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.subscribers[s] {
+		delete(f.subscribers, s)
+		close(s)
+	}
+}
+
+// ServeHTTP streams deltas to the client until it goes away or falls behind.
+func (f *changeFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s := f.subscribe()
+	defer f.unsubscribe(s)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case d, ok := <-s:
+			if !ok {
+				return
+			}
+			if err := enc.Encode(d); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
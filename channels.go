@@ -0,0 +1,190 @@
+package main
+
+// channelSet selects which auxiliary per-cell channels are tracked.
+type channelSet uint8
+
+const (
+	channelAge channelSet = 1 << iota
+	channelLineage
+	channelOwner
+	channelHeat
+)
+
+// heatDecay is how much of a cell's heat is left after each generation it
+// spends dead.
+const heatDecay = 0.9
+
+// channels carries optional per-cell data alongside the board. Each channel is
+// its own slice indexed by x*rows+y, so a renderer or exporter can hand a
+// whole channel to the GPU or a file without walking the cells. Channels that
+// weren't asked for are left nil.
+type channels struct {
+	rows int
+
+	age     []uint32  // generations the cell has been alive in a row
+	lineage []uint32  // id of the initial cell this one descends from, 0 if none
+	owner   []uint8   // who placed the cell or most of its parents
+	heat    []float32 // 1 while alive, decaying towards 0 after death
+
+	previous []bool
+}
+
+func newChannels(cells [][]*cell, set channelSet) *channels {
+	size := columns * rows
+	ch := &channels{
+		rows:     rows,
+		previous: make([]bool, size),
+	}
+
+	if set&channelAge != 0 {
+		ch.age = make([]uint32, size)
+	}
+	if set&channelLineage != 0 {
+		ch.lineage = make([]uint32, size)
+	}
+	if set&channelOwner != 0 {
+		ch.owner = make([]uint8, size)
+	}
+	if set&channelHeat != 0 {
+		ch.heat = make([]float32, size)
+	}
+
+	for x := range cells {
+		for y, c := range cells[x] {
+			i := ch.index(x, y)
+			ch.previous[i] = c.alive()
+			if !c.alive() {
+				continue
+			}
+			if ch.age != nil {
+				ch.age[i] = 1
+			}
+			if ch.lineage != nil {
+				ch.lineage[i] = uint32(i + 1)
+			}
+			if ch.heat != nil {
+				ch.heat[i] = 1
+			}
+		}
+	}
+
+	return ch
+}
+
+func (ch *channels) index(x, y int) int {
+	return x*ch.rows + y
+}
+
+// update moves every channel on to the generation the cells are now in.
+func (ch *channels) update(cells [][]*cell) {
+	var births []int
+	var parents [][]int
+
+	for x := range cells {
+		for y, c := range cells[x] {
+			i := ch.index(x, y)
+			wasAlive := ch.previous[i]
+
+			if ch.age != nil {
+				if c.alive() {
+					ch.age[i]++
+				} else {
+					ch.age[i] = 0
+				}
+			}
+			if ch.heat != nil {
+				if c.alive() {
+					ch.heat[i] = 1
+				} else {
+					ch.heat[i] *= heatDecay
+				}
+			}
+
+			// Births inherit from the neighbors that were alive last
+			// generation, which is only known before previous is overwritten.
+			if c.alive() && !wasAlive && (ch.lineage != nil || ch.owner != nil) {
+				births = append(births, i)
+				parents = append(parents, ch.liveNeighbors(x, y, len(cells)))
+			}
+		}
+	}
+
+	for n, i := range births {
+		if ch.lineage != nil {
+			ch.lineage[i] = majority(pick(ch.lineage, parents[n]))
+		}
+		if ch.owner != nil {
+			ch.owner[i] = uint8(majority(ownerIDs(ch.owner, parents[n])))
+		}
+	}
+
+	for x := range cells {
+		for y, c := range cells[x] {
+			i := ch.index(x, y)
+			if !c.alive() {
+				if ch.lineage != nil {
+					ch.lineage[i] = 0
+				}
+				if ch.owner != nil {
+					ch.owner[i] = 0
+				}
+			}
+			ch.previous[i] = c.alive()
+		}
+	}
+}
+
+// liveNeighbors returns the indices of the neighbors of x, y that were alive
+// last generation, treating the edges the same way the simulation does.
+func (ch *channels) liveNeighbors(x, y, columns int) []int {
+	var live []int
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			nx, ny, ok := boundary.resolve(x+dx, y+dy, columns, ch.rows)
+			if !ok {
+				continue
+			}
+			if i := ch.index(nx, ny); ch.previous[i] {
+				live = append(live, i)
+			}
+		}
+	}
+	return live
+}
+
+// majority returns the most common of values. Ties go to the smaller value so
+// the result doesn't depend on neighbor order.
+func majority(values []uint32) uint32 {
+	counts := map[uint32]int{}
+	for _, v := range values {
+		counts[v]++
+	}
+
+	var best uint32
+	bestCount := 0
+	for v, count := range counts {
+		if count > bestCount || (count == bestCount && v < best) {
+			best, bestCount = v, count
+		}
+	}
+	return best
+}
+
+func pick(values []uint32, indices []int) []uint32 {
+	picked := make([]uint32, len(indices))
+	for n, i := range indices {
+		picked[n] = values[i]
+	}
+	return picked
+}
+
+func ownerIDs(owner []uint8, indices []int) []uint32 {
+	ids := make([]uint32, len(indices))
+	for n, i := range indices {
+		ids[n] = uint32(owner[i])
+	}
+	return ids
+}
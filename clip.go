@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/jake-shasteen/golang-gl-conway-life/pattern"
+	"os"
+	"strings"
+)
+
+// clip is a self-contained demo, saved as JSON in a .lifeclip file. It holds
+// everything that decides what's on screen, so a clip plays back the same on
+// any machine.
+type clip struct {
+	Rule     string `json:"rule"`
+	Boundary string `json:"boundary"`
+	Columns  int    `json:"columns"`
+	Rows     int    `json:"rows"`
+	Palette  string `json:"palette"`
+
+	// Board is the starting pattern in RLE, cropped to its live cells, and
+	// Origin the board cell at its top left. Without Origin, as in a clip
+	// written by hand, the pattern is centered on the board.
+	Board  string  `json:"board"`
+	Origin *[2]int `json:"origin,omitempty"`
+
+	// Speed and Camera are keyframes by generation. The speed changes at
+	// each keyframe; the camera moves smoothly from one to the next.
+	Speed  []speedKey `json:"speed,omitempty"`
+	Camera cameraPath `json:"camera,omitempty"`
+}
+
+type speedKey struct {
+	Generation int     `json:"generation"`
+	TPS        float64 `json:"tps"`
+}
+
+type cameraKey struct {
+	Generation int `json:"generation"`
+	camera
+}
+
+// cameraPath is a camera move keyframed by generation, as played by a clip or
+// given with --camera-path.
+type cameraPath []cameraKey
+
+// loadCameraPath reads and checks a camera path saved as JSON at path.
+func loadCameraPath(path string) (cameraPath, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p cameraPath
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	if err := p.check(); err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	return p, nil
+}
+
+// check returns an error if a keyframe has no zoom or comes before the one
+// ahead of it.
+func (p cameraPath) check() error {
+	for i, k := range p {
+		if k.Zoom <= 0 {
+			return fmt.Errorf("camera keyframe %d needs a positive zoom", i)
+		}
+		if i > 0 && k.Generation < p[i-1].Generation {
+			return fmt.Errorf("camera keyframe %d comes before the one ahead of it", i)
+		}
+	}
+	return nil
+}
+
+// loadClip reads and checks the clip at path.
+func loadClip(path string) (*clip, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c clip
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	if _, err := pattern.ReadRLE(strings.NewReader(c.Board)); err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	for i, k := range c.Speed {
+		if !(k.TPS > 0 && k.TPS <= maxTPS) {
+			return nil, fmt.Errorf("reading %v: speed keyframe %d must be above 0 and at most %.0f", path, i, maxTPS)
+		}
+	}
+	if err := c.Camera.check(); err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	return &c, nil
+}
+
+// saveClip writes a clip of the board as it is now, which can be edited by
+// hand to add camera moves and speed changes. The camera follows moves, if
+// there are any.
+func saveClip(path string, cells [][]*cell, palette string, tps float64, moves cameraPath) error {
+	alive := snapshot(cells)
+	var board bytes.Buffer
+	if err := encodeRLE(&board, alive, ruleName()); err != nil {
+		return err
+	}
+	var origin *[2]int
+	if minX, _, _, maxY, ok := boundingBox(alive); ok {
+		origin = &[2]int{minX, maxY}
+	}
+
+	c := clip{
+		Rule:     ruleName(),
+		Boundary: boundary.String(),
+		Columns:  len(cells),
+		Rows:     len(cells[0]),
+		Palette:  palette,
+		Board:    board.String(),
+		Origin:   origin,
+		Speed:    []speedKey{{0, tps}},
+		Camera:   moves,
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// stamp replaces the board with the clip's starting pattern.
+func (c *clip) stamp(b *board) {
+	p, _ := pattern.ReadRLE(strings.NewReader(c.Board))
+	b.clear()
+	if c.Origin != nil {
+		b.stampAt(p.Rows, c.Origin[0], c.Origin[1])
+		return
+	}
+	b.stamp(p.Rows)
+}
+
+// speedAt returns the speed set by the last keyframe at or before
+// generation, or false if there isn't one.
+func (c *clip) speedAt(generation int) (float64, bool) {
+	tps, ok := 0.0, false
+	for _, k := range c.Speed {
+		if k.Generation <= generation {
+			tps, ok = k.TPS, true
+		}
+	}
+	return tps, ok
+}
+
+// at returns where the camera is at generation, which may be part way
+// between two generations, moving in a straight line between keyframes.
+func (p cameraPath) at(generation float64) camera {
+	if len(p) == 0 {
+		return camera{Zoom: 1}
+	}
+
+	prev := p[0]
+	if generation <= float64(prev.Generation) {
+		return prev.camera
+	}
+	for _, next := range p[1:] {
+		if generation < float64(next.Generation) {
+			t := float32((generation - float64(prev.Generation)) / float64(next.Generation-prev.Generation))
+			return camera{
+				X:    prev.X + (next.X-prev.X)*t,
+				Y:    prev.Y + (next.Y-prev.Y)*t,
+				Zoom: prev.Zoom + (next.Zoom-prev.Zoom)*t,
+			}
+		}
+		prev = next
+	}
+	return prev.camera
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestClipOrigin checks that a clip puts its pattern back where it was on the
+// board, rather than in the middle.
+func TestClipOrigin(t *testing.T) {
+	rows := []string{
+		"..........",
+		".O........",
+		"..O.......",
+		"OOO.......",
+		"..........",
+		"..........",
+		"......OO..",
+		"......O...",
+	}
+	useRule(t, "B3/S23", len(rows[0]), len(rows))
+
+	b := &board{cells: renderCells(rows), engine: naiveEngine{}}
+	path := filepath.Join(t.TempDir(), "test.lifeclip")
+	if err := saveClip(path, b.cells, "gradient", 10, nil); err != nil {
+		t.Fatal(err)
+	}
+	c, err := loadClip(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	played := &board{cells: renderCells(rows), engine: naiveEngine{}}
+	played.clear()
+	c.stamp(played)
+	if !reflect.DeepEqual(snapshot(played.cells), snapshot(b.cells)) {
+		t.Error("the clip's pattern moved when it was played")
+	}
+}
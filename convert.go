@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/jake-shasteen/golang-gl-conway-life/pattern"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// patternFormats are the formats convert writes, by name, each with the file
+// extensions that pick it when --format isn't given.
+var patternFormats = []struct {
+	name       string
+	extensions []string
+}{
+	{"rle", []string{".rle"}},
+	{"cells", []string{".cells", ".txt"}},
+	{"life105", nil},
+	{"life106", []string{".lif", ".life"}},
+	{"mc", []string{".mc"}},
+	{"png", []string{".png"}},
+}
+
+// convert reads a pattern in any format the game loads and writes it in
+// another, without opening a window. The formats are told from the file
+// names unless --format says otherwise; PNG is a picture with a pixel for
+// each cell, which keeps the rule and generation too.
+func convert(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	format := fs.String("format", "", "format to write: rle, cells, life105, life106, mc or png; by default it's told from the output file's extension")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: conway convert [--format name] in out")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	in, out := fs.Arg(0), fs.Arg(1)
+
+	if *format == "" {
+		ext := strings.ToLower(filepath.Ext(out))
+		for _, f := range patternFormats {
+			for _, e := range f.extensions {
+				if e == ext {
+					*format = f.name
+				}
+			}
+		}
+		if *format == "" {
+			log.Fatalf("can't tell the format of %v from its extension; give one with --format", out)
+		}
+	}
+
+	var p *pattern.Pattern
+	if strings.HasSuffix(strings.ToLower(in), ".mc") {
+		m, err := loadMacrocell(in)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		p = m.pattern()
+	} else {
+		var err error
+		if p, err = pattern.Load(in); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer f.Close()
+	if err := writePattern(f, p, *format); err != nil {
+		log.Fatalf("writing %v: %v", out, err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// writePattern writes p to w in the format called name.
+func writePattern(w io.Writer, p *pattern.Pattern, name string) error {
+	switch name {
+	case "rle":
+		return p.WriteRLE(w)
+	case "cells":
+		return p.WritePlaintext(w)
+	case "life105":
+		return p.WriteLife105(w)
+	case "life106":
+		return p.WriteLife106(w)
+	case "mc":
+		return newMacrocell(patternCells(p), p.Rule).write(w)
+	case "png":
+		return p.WritePNG(w)
+	}
+	return fmt.Errorf("unknown format %q, want rle, cells, life105, life106, mc or png", name)
+}
+
+// patternCells returns a board just big enough to hold p, with p on it.
+func patternCells(p *pattern.Pattern) [][]*cell {
+	width, height := 1, len(p.Rows)
+	for _, row := range p.Rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	if height == 0 {
+		height = 1
+	}
+
+	cells := make([][]*cell, width)
+	for x := range cells {
+		for y := 0; y < height; y++ {
+			cells[x] = append(cells[x], newCell(x, y))
+		}
+	}
+	(&board{cells: cells}).stamp(p.Rows)
+	return cells
+}
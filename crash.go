@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// issuesURL is where crashes should be reported.
+const issuesURL = "https://github.com/jake-shasteen/golang-gl-conway-life/issues"
+
+// crashReporter turns a panic into a crash bundle: a directory holding the
+// board as RLE and a report of the rule, seed, generation, machine and stack,
+// so a maintainer can reproduce the crash. Fields that haven't been filled in
+// yet are left out of the report.
+type crashReporter struct {
+	cells      [][]*cell
+	generation *int
+
+	// gl describes the OpenGL context. It is gathered when the context is
+	// made, since it can't be queried from the step goroutine, and may be
+	// unusable by the time of a crash.
+	gl []string
+}
+
+// glDiagnostics describes the current OpenGL context.
+func glDiagnostics() []string {
+	return []string{
+		"OpenGL: " + gl.GoStr(gl.GetString(gl.VERSION)),
+		"GLSL: " + gl.GoStr(gl.GetString(gl.SHADING_LANGUAGE_VERSION)),
+		fmt.Sprintf("Renderer: %v (%v)", gl.GoStr(gl.GetString(gl.RENDERER)), gl.GoStr(gl.GetString(gl.VENDOR))),
+	}
+}
+
+// handle recovers from a panic, writes a crash bundle and tells the user how
+// to report it, then exits. It must be deferred directly, at the top of each
+// goroutine that touches the board.
+//
+// The board is read without the simulation lock, which the panicking code may
+// hold, so it may be caught between generations.
+func (r *crashReporter) handle() {
+	v := recover()
+	if v == nil {
+		return
+	}
+	stack := debug.Stack()
+	fmt.Fprintf(os.Stderr, "panic: %v\n\n%s\n", v, stack)
+
+	dir, err := r.write(v, stack)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "The game crashed, and the crash bundle couldn't be written:", err)
+		os.Exit(2)
+	}
+
+	fmt.Fprintf(os.Stderr, "The game crashed. Details were saved to %v\n", dir)
+	fmt.Fprintf(os.Stderr, "Please open an issue at %v saying what you were doing, and attach the files in that directory.\n", issuesURL)
+	if r.cells != nil {
+		// The pattern's header holds the rule, whether B/S or Larger than
+		// Life, and --pattern runs it.
+		fmt.Fprintf(os.Stderr, "To start again from the board as it crashed, run with --pattern %v\n",
+			filepath.Join(dir, "board.rle"))
+	}
+	os.Exit(2)
+}
+
+// write saves the crash bundle to a new directory and returns its path.
+func (r *crashReporter) write(v interface{}, stack []byte) (string, error) {
+	dir, err := os.MkdirTemp("", "conway-crash-")
+	if err != nil {
+		return "", err
+	}
+
+	report, err := os.Create(filepath.Join(dir, "report.txt"))
+	if err != nil {
+		return "", err
+	}
+	defer report.Close()
+	r.report(report, v, stack)
+	if err := report.Close(); err != nil {
+		return "", err
+	}
+
+	if r.cells != nil {
+		board, err := os.Create(filepath.Join(dir, "board.rle"))
+		if err != nil {
+			return "", err
+		}
+		defer board.Close()
+		if err := encodeRLE(board, snapshot(r.cells), ruleName()); err != nil {
+			return "", err
+		}
+		if err := board.Close(); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+func (r *crashReporter) report(w io.Writer, v interface{}, stack []byte) {
+	fmt.Fprintf(w, "Panic: %v\n", v)
+	fmt.Fprintf(w, "Time: %v\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(w, "Command: %v\n", strings.Join(os.Args, " "))
+	fmt.Fprintf(w, "Go: %v %v/%v, %v CPUs\n", runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	for _, line := range r.gl {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "Seed: %v\n", seed)
+	fmt.Fprintf(w, "Rule: %v\n", ruleName())
+	fmt.Fprintf(w, "Boundary: %v\n", boundary)
+	fmt.Fprintf(w, "Board: %vx%v\n", columns, rows)
+	if r.generation != nil {
+		fmt.Fprintf(w, "Generation: %v\n", *r.generation)
+	}
+	if r.cells != nil {
+		fmt.Fprintf(w, "Population: %v\n", population(r.cells))
+	}
+	fmt.Fprintf(w, "\n%s", stack)
+}
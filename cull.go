@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// cullPolicy decides which cells die when the board is over its population
+// cap.
+type cullPolicy int
+
+const (
+	cullRandom cullPolicy = iota // any live cell, at random
+	cullOldest                   // the cells that have been alive longest
+	cullEdge                     // the cells nearest the edge of the board
+)
+
+func (p cullPolicy) String() string {
+	switch p {
+	case cullRandom:
+		return "random"
+	case cullOldest:
+		return "oldest"
+	case cullEdge:
+		return "edge"
+	}
+	return "unknown"
+}
+
+// Set parses a policy name, so a cullPolicy can be used as a flag.
+func (p *cullPolicy) Set(name string) error {
+	for _, policy := range []cullPolicy{cullRandom, cullOldest, cullEdge} {
+		if policy.String() == name {
+			*p = policy
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown cull policy %q, want random, oldest or edge", name)
+}
+
+// cull kills live cells under policy until at most limit are left, and
+// returns how many it killed. Ages come from meta, which should not have
+// been updated for the current generation yet, so newborns count as
+// youngest. Ties are broken at random.
+func cull(cells [][]*cell, meta *channels, limit int, policy cullPolicy) int {
+	var alive []point
+	for x := range cells {
+		for y, c := range cells[x] {
+			if c.alive() {
+				alive = append(alive, point{x, y})
+			}
+		}
+	}
+	excess := len(alive) - limit
+	if excess <= 0 {
+		return 0
+	}
+
+	rand.Shuffle(len(alive), func(i, j int) { alive[i], alive[j] = alive[j], alive[i] })
+
+	switch {
+	case policy == cullOldest && meta.age != nil:
+		age := func(p point) uint32 { return meta.age[meta.index(p.x, p.y)] }
+		sort.SliceStable(alive, func(i, j int) bool { return age(alive[i]) > age(alive[j]) })
+	case policy == cullEdge:
+		edge := func(p point) int {
+			d := p.x
+			for _, e := range []int{p.y, len(cells) - 1 - p.x, len(cells[0]) - 1 - p.y} {
+				if e < d {
+					d = e
+				}
+			}
+			return d
+		}
+		sort.SliceStable(alive, func(i, j int) bool { return edge(alive[i]) < edge(alive[j]) })
+	}
+
+	for _, p := range alive[:excess] {
+		cells[p.x][p.y].set(false)
+	}
+	return excess
+}
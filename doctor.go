@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"io"
+	"runtime"
+	"time"
+)
+
+// benchmarkGenerations is how many generations doctor times the simulation for.
+const benchmarkGenerations = 50
+
+// glVersions are the core profile versions doctor tries, best first.
+var glVersions = [][2]int{{4, 6}, {4, 5}, {4, 4}, {4, 3}, {4, 2}, {4, 1}, {4, 0}, {3, 3}}
+
+// doctor prints a report of what the machine supports, meant to be pasted into
+// bug reports. It never opens a visible window.
+func doctor(w io.Writer) {
+	fmt.Fprintf(w, "Go: %v %v/%v, %v CPUs\n", runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+
+	if err := glfw.Init(); err != nil {
+		fmt.Fprintf(w, "GLFW: init failed: %v\n", err)
+		return
+	}
+	defer glfw.Terminate()
+	fmt.Fprintf(w, "GLFW: %v\n", glfw.GetVersionString())
+
+	for _, m := range glfw.GetMonitors() {
+		mode := m.GetVideoMode()
+		scaleX, scaleY := m.GetContentScale()
+		widthMM, heightMM := m.GetPhysicalSize()
+		fmt.Fprintf(w, "Monitor %q: %vx%v @ %vHz, scale %vx%v, %vx%vmm\n",
+			m.GetName(), mode.Width, mode.Height, mode.RefreshRate, scaleX, scaleY, widthMM, heightMM)
+	}
+
+	var window *glfw.Window
+	for _, v := range glVersions {
+		glfw.DefaultWindowHints()
+		glfw.WindowHint(glfw.Visible, glfw.False)
+		glfw.WindowHint(glfw.ContextVersionMajor, v[0])
+		glfw.WindowHint(glfw.ContextVersionMinor, v[1])
+		glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+		glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+
+		var err error
+		if window, err = glfw.CreateWindow(64, 64, "doctor", nil, nil); err == nil {
+			fmt.Fprintf(w, "Best core context: %v.%v\n", v[0], v[1])
+			break
+		}
+	}
+	if window == nil {
+		fmt.Fprintln(w, "OpenGL: no core context could be created")
+		return
+	}
+	defer window.Destroy()
+	window.MakeContextCurrent()
+
+	if err := gl.Init(); err != nil {
+		fmt.Fprintf(w, "OpenGL: 4.1 bindings failed to load: %v\n", err)
+		return
+	}
+	for _, line := range glDiagnostics() {
+		fmt.Fprintln(w, line)
+	}
+
+	for _, ext := range []struct{ name, feature string }{
+		{"GL_ARB_compute_shader", "compute shaders"},
+		{"GL_ARB_shader_storage_buffer_object", "SSBOs"},
+		{"GL_KHR_debug", "debug output"},
+		{"GL_ARB_shader_atomic_counters", "atomic counters"},
+	} {
+		fmt.Fprintf(w, "%v (%v): %v\n", ext.feature, ext.name, glfw.ExtensionSupported(ext.name))
+	}
+
+	b := newBoard()
+	start := time.Now()
+	for i := 0; i < benchmarkGenerations; i++ {
+		b.Step()
+	}
+	elapsed := time.Since(start)
+	fmt.Fprintf(w, "Benchmark: %v generations of %vx%v in %v (%.0f gens/sec)\n",
+		benchmarkGenerations, columns, rows, elapsed, benchmarkGenerations/elapsed.Seconds())
+}
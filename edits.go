@@ -0,0 +1,64 @@
+package main
+
+import "math"
+
+type edit struct {
+	x, y  int
+	alive bool
+}
+
+// editQueue holds cell edits made with the mouse until the simulation is
+// between generations, so an edit never lands halfway through a step. The
+// caller is expected to hold the same lock as the step loop.
+type editQueue struct {
+	pending []edit
+}
+
+// add queues e, replacing any edit already waiting for the same cell.
+func (q *editQueue) add(e edit) {
+	for i := range q.pending {
+		if q.pending[i].x == e.x && q.pending[i].y == e.y {
+			q.pending[i] = e
+			return
+		}
+	}
+	q.pending = append(q.pending, e)
+}
+
+// apply makes every pending edit and empties the queue.
+func (q *editQueue) apply(cells [][]*cell) {
+	for _, e := range q.pending {
+		cells[e.x][e.y].set(e.alive)
+	}
+	q.pending = q.pending[:0]
+}
+
+// boardScale mirrors the vertex shader, which divides every position by a
+// factor that pulses between 0.9 and 1.0 over time.
+func boardScale(seconds float64) float64 {
+	return 0.9 + math.Abs(math.Sin(seconds/2))/10
+}
+
+// windowToBoard maps the window coordinates xpos, ypos to the point of the
+// board under them, in the board's -1 to 1 coordinates.
+func windowToBoard(xpos, ypos, seconds float64) (float64, float64) {
+	scale := boardScale(seconds)
+	projectionX, projectionY := projection(width, height)
+	return view.toBoard(
+		((xpos/float64(width)*2-1)/float64(projectionX)-float64(viewOffset))/float64(viewScale)*scale,
+		(1-ypos/float64(height)*2)/float64(projectionY)*scale,
+	)
+}
+
+// cellAt returns the cell under the window coordinates xpos, ypos, or false if
+// the point is off the board.
+func cellAt(cells [][]*cell, xpos, ypos, seconds float64) (int, int, bool) {
+	ndcX, ndcY := windowToBoard(xpos, ypos, seconds)
+
+	x := int(math.Floor((ndcX + 1) / 2 * float64(columns)))
+	y := int(math.Floor((ndcY + 1) / 2 * float64(rows)))
+	if x < 0 || x >= len(cells) || y < 0 || y >= len(cells[x]) {
+		return 0, 0, false
+	}
+	return x, y, true
+}
@@ -0,0 +1,65 @@
+package main
+
+import "fmt"
+
+// engine works out future generations of the board.
+type engine interface {
+	// advance moves cells on by 2^k generations.
+	advance(cells [][]*cell, k int)
+
+	// reset forgets anything kept from earlier advances, for when the whole
+	// board has been replaced rather than edited.
+	reset()
+}
+
+// newEngine returns the engine called name. It must be called on the main
+// thread, after GLFW is initialized.
+func newEngine(name string) (engine, error) {
+	switch name {
+	case "packed":
+		return &packedEngine{}, nil
+	case "naive":
+		return naiveEngine{}, nil
+	case "hashlife":
+		return newHashlife(), nil
+	case "gpu":
+		return newGPUEngine()
+	case "sparse":
+		return newSparseEngine(), nil
+	}
+	return nil, fmt.Errorf("unknown engine %q, want packed, naive, hashlife, gpu or sparse", name)
+}
+
+// naiveEngine visits every cell every generation. It supports every rule and
+// boundary mode.
+type naiveEngine struct{}
+
+// advance steps one generation at a time. Every cell's next state is decided
+// from the current generation before any cell changes, so the result doesn't
+// depend on the order the cells are visited in, and bands of rows can be
+// worked out in parallel.
+func (naiveEngine) advance(cells [][]*cell, k int) {
+	cellWrites++
+	for i := 0; i < 1<<k; i++ {
+		if activeLtL != nil {
+			stepLtL(activeLtL, cells)
+		} else {
+			inBands(len(cells[0]), func(y0, y1 int) {
+				for x := range cells {
+					for _, c := range cells[x][y0:y1] {
+						c.checkState(cells)
+					}
+				}
+			})
+		}
+		inBands(len(cells[0]), func(y0, y1 int) {
+			for x := range cells {
+				for _, c := range cells[x][y0:y1] {
+					c.state = c.stateNext
+				}
+			}
+		})
+	}
+}
+
+func (naiveEngine) reset() {}
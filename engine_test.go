@@ -0,0 +1,126 @@
+package main
+
+import (
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+	"reflect"
+	"testing"
+)
+
+// useRule sets the rule and board size for a test, and puts them back after.
+func useRule(t *testing.T, name string, width, height int) {
+	t.Helper()
+	r, err := life.LookupRule(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedColumns, savedRows, savedRule := columns, rows, activeRule
+	t.Cleanup(func() {
+		columns, rows = savedColumns, savedRows
+		setRule(savedRule)
+	})
+	columns, rows = width, height
+	setRule(r)
+}
+
+// TestSparseReset checks that a sparse board put back to its start runs as a
+// fresh one would, without the gliders that left the window last time.
+func TestSparseReset(t *testing.T) {
+	useRule(t, "B3/S23", 16, 16)
+	start := []string{
+		"................",
+		"................",
+		"................",
+		"................",
+		"................",
+		"........OOO.....",
+		"........O.......",
+		".........O......",
+		"......O.........",
+		".......O........",
+		".....OOO........",
+		"................",
+		"................",
+		"................",
+		"................",
+		"................",
+	}
+
+	sparse := newSparseEngine()
+	b := &board{cells: renderCells(start), engine: sparse}
+	initial := snapshot(b.cells)
+	for i := 0; i < 40; i++ {
+		b.Step()
+	}
+	b.restore(initial)
+	for i := 0; i < 5; i++ {
+		b.Step()
+	}
+
+	freshSparse := newSparseEngine()
+	fresh := &board{cells: renderCells(start), engine: freshSparse}
+	for i := 0; i < 5; i++ {
+		fresh.Step()
+	}
+
+	if !reflect.DeepEqual(snapshot(b.cells), snapshot(fresh.cells)) {
+		t.Error("the reset board differs from a fresh one")
+	}
+	if !reflect.DeepEqual(sparse.live, freshSparse.live) || sparse.origin != freshSparse.origin {
+		t.Errorf("the reset plane has %v cells at %v, a fresh one %v at %v",
+			len(sparse.live), sparse.origin, len(freshSparse.live), freshSparse.origin)
+	}
+}
+
+// TestHashlifeWarp checks that a warp on the hashlife engine ends where as
+// many steps on the naive engine do on a dead-edged board, including for a
+// glider that reaches the edge partway through, which hashlife leaves to the
+// naive engine.
+func TestHashlifeWarp(t *testing.T) {
+	savedBoundary := boundary
+	t.Cleanup(func() { boundary = savedBoundary })
+	boundary = boundaryDead
+	useRule(t, "B3/S23", 32, 32)
+
+	for _, c := range []struct {
+		name     string
+		pattern  []string
+		x, y, k  int
+		hashlife bool
+	}{
+		{"r-pentomino in the middle", []string{".OO", "OO.", ".O."}, 15, 15, 3, true},
+		{"glider reaching the edge", []string{".O.", "..O", "OOO"}, 26, 4, 4, false},
+	} {
+		cells := func() [][]*cell {
+			cells := make([][]*cell, columns)
+			for x := range cells {
+				for y := 0; y < rows; y++ {
+					cells[x] = append(cells[x], newCell(x, y))
+				}
+			}
+			for row, line := range c.pattern {
+				for i, ch := range line {
+					// Rows are given top first, and y runs up.
+					cells[c.x+i][c.y+len(c.pattern)-1-row].set(ch == 'O')
+				}
+			}
+			return cells
+		}
+		h := newHashlife()
+		warped := &board{cells: cells(), engine: h}
+		stepped := &board{cells: cells(), engine: naiveEngine{}}
+		warped.Warp(c.k)
+		for i := 0; i < 1<<c.k; i++ {
+			stepped.Step()
+		}
+
+		if used := len(h.nodes) > 0; used != c.hashlife {
+			t.Errorf("%v: hashlife used %v, want %v", c.name, used, c.hashlife)
+		}
+		if population(stepped.cells) == 0 {
+			t.Errorf("%v: the pattern died out, which tests nothing", c.name)
+		}
+		if !reflect.DeepEqual(snapshot(warped.cells), snapshot(stepped.cells)) {
+			t.Errorf("%v: warping %v generations differs from stepping them", c.name, 1<<c.k)
+		}
+	}
+}
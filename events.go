@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"time"
+)
+
+// eventQueueSize is how many events may wait to be delivered before new ones
+// are dropped, so a slow webhook never holds up the simulation.
+const eventQueueSize = 64
+
+type event struct {
+	Name       string    `json:"event"`
+	Generation int       `json:"generation"`
+	Population int       `json:"population"`
+	Time       time.Time `json:"time"`
+}
+
+// notifier POSTs events as JSON to a webhook. A nil notifier discards them.
+type notifier struct {
+	url    string
+	client *http.Client
+	queue  chan event
+}
+
+// newNotifier returns a notifier delivering to url, or nil if url is empty.
+func newNotifier(url string) *notifier {
+	if url == "" {
+		return nil
+	}
+
+	n := &notifier{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		queue:  make(chan event, eventQueueSize),
+	}
+	go n.deliver()
+	return n
+}
+
+func (n *notifier) emit(name string, generation, population int) {
+	if n == nil {
+		return
+	}
+
+	select {
+	case n.queue <- event{Name: name, Generation: generation, Population: population, Time: time.Now()}:
+	default:
+		log.Println("dropping event", name, "- webhook queue is full")
+	}
+}
+
+func (n *notifier) deliver() {
+	for e := range n.queue {
+		body, err := json.Marshal(e)
+		if err != nil {
+			log.Println("encoding event:", err)
+			continue
+		}
+
+		resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Println("sending event:", err)
+			continue
+		}
+		resp.Body.Close()
+	}
+}
+
+// watcher follows the board from generation to generation and emits extinct
+// and stagnated the first time the board reaches either state.
+type watcher struct {
+	generation int
+	population int
+	hashes     [2]uint64
+
+	extinct  bool
+	stagnant bool
+}
+
+// observe records that the board has moved on by generations. The board counts as stagnated when it is
+// identical to one of the last two generations, which covers still lifes and
+// period-2 oscillators.
+func (w *watcher) observe(cells [][]*cell, n *notifier, generations int) {
+	w.generation += generations
+
+	h := fnv.New64a()
+	population := 0
+	for x := range cells {
+		for _, c := range cells[x] {
+			if c.alive() {
+				population++
+			}
+			h.Write([]byte{c.state})
+		}
+	}
+	sum := h.Sum64()
+	w.population = population
+
+	if population == 0 {
+		if !w.extinct {
+			n.emit("extinct", w.generation, population)
+		}
+		w.extinct = true
+	} else {
+		w.extinct = false
+	}
+
+	if sum == w.hashes[0] || sum == w.hashes[1] {
+		if !w.stagnant && !w.extinct {
+			n.emit("stagnated", w.generation, population)
+		}
+		w.stagnant = true
+	} else {
+		w.stagnant = false
+	}
+
+	w.hashes[1], w.hashes[0] = w.hashes[0], sum
+}
+
+// population returns the number of live cells.
+func population(cells [][]*cell) int {
+	count := 0
+	for x := range cells {
+		for _, c := range cells[x] {
+			if c.alive() {
+				count++
+			}
+		}
+	}
+	return count
+}
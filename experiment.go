@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// experimentSpec is a grid of runs for conway experiment, read from JSON such
+// as
+//
+//	{"width": 128, "height": 128, "generations": 1000,
+//	 "rules": ["conway", "highlife", "B36/S125"],
+//	 "seeds": [1, 2, 3], "densities": [0.1, 0.3, 0.5]}
+//
+// Every rule is run from every seed at every density.
+type experimentSpec struct {
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	Boundary    string    `json:"boundary"`
+	Generations int       `json:"generations"`
+	Strict      bool      `json:"strict"`
+	Rules       []string  `json:"rules"`
+	Seeds       []int64   `json:"seeds"`
+	Densities   []float64 `json:"densities"`
+}
+
+// experimentRun is one combination from the spec and what became of it.
+// ExtinctAt and SettledAt are -1 if it never died out or settled.
+type experimentRun struct {
+	Rule    string
+	Seed    int64
+	Density float64
+
+	Population int
+	Peak       int
+	ExtinctAt  int
+	SettledAt  int
+}
+
+// loadExperiment reads the spec at path, filling in the defaults.
+func loadExperiment(path string) (*experimentSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &experimentSpec{Width: 64, Height: 64, Generations: 500}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	if len(spec.Rules) == 0 {
+		spec.Rules = []string{life.Conway.String()}
+	}
+	if len(spec.Seeds) == 0 {
+		spec.Seeds = []int64{1}
+	}
+	if len(spec.Densities) == 0 {
+		spec.Densities = []float64{threshold}
+	}
+
+	if spec.Width <= 0 || spec.Height <= 0 || spec.Generations <= 0 {
+		return nil, fmt.Errorf("reading %v: width, height and generations must be positive", path)
+	}
+	for _, d := range spec.Densities {
+		if d < 0 || d > 1 {
+			return nil, fmt.Errorf("reading %v: density %v isn't between 0 and 1", path, d)
+		}
+	}
+	for _, s := range spec.Seeds {
+		if s == 0 {
+			return nil, fmt.Errorf("reading %v: seeds can't be 0, which leaves the board empty", path)
+		}
+	}
+	// New checks the rules and boundary, so a typo fails now rather than
+	// partway through.
+	for _, r := range spec.Rules {
+		if _, err := life.New(spec.config(r, 1, 0)); err != nil {
+			return nil, fmt.Errorf("reading %v: %v", path, err)
+		}
+	}
+	return spec, nil
+}
+
+func (s *experimentSpec) config(rule string, seed int64, density float64) life.Config {
+	return life.Config{
+		Width:    s.Width,
+		Height:   s.Height,
+		Rule:     rule,
+		Boundary: s.Boundary,
+		Seed:     seed,
+		Density:  density,
+		Strict:   s.Strict,
+	}
+}
+
+// experiment runs every combination in a spec without opening a window, in
+// parallel, and writes a line of CSV for each, in the order the spec lists
+// them, so batches can be compared without any shell scripting.
+func experiment(args []string, w io.Writer) {
+	fs := flag.NewFlagSet("experiment", flag.ExitOnError)
+	out := fs.String("out", "", "write the CSV to `file` instead of stdout")
+	parallel := fs.Int("parallel", runtime.NumCPU(), "how many runs to work on at once")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: conway experiment [flags] spec.json")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *parallel <= 0 {
+		log.Fatalln("--parallel must be positive")
+	}
+
+	spec, err := loadExperiment(fs.Arg(0))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	runs := spec.run(*parallel)
+	if *out == "" {
+		if err := writeExperiment(w, runs); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer f.Close()
+	if err := writeExperiment(f, runs); err != nil {
+		log.Fatalf("writing %v: %v", *out, err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// run works through every combination, parallel at a time.
+func (s *experimentSpec) run(parallel int) []experimentRun {
+	var runs []experimentRun
+	for _, r := range s.Rules {
+		for _, seed := range s.Seeds {
+			for _, d := range s.Densities {
+				runs = append(runs, experimentRun{Rule: r, Seed: seed, Density: d})
+			}
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				s.runOne(&runs[j])
+			}
+		}()
+	}
+	for j := range runs {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+	return runs
+}
+
+// runOne plays out one combination. Like the watcher's stagnated event, it
+// has settled the first time the board is the same as one of the two before.
+func (s *experimentSpec) runOne(run *experimentRun) {
+	g, err := life.New(s.config(run.Rule, run.Seed, run.Density))
+	if err != nil {
+		// loadExperiment already made a game with every rule.
+		panic(err)
+	}
+	run.Rule = g.Rule()
+	run.ExtinctAt, run.SettledAt = -1, -1
+
+	var hashes [2]uint64
+	for generation := 0; ; generation++ {
+		population := 0
+		h := fnv.New64a()
+		for _, row := range g.Snapshot() {
+			for _, alive := range row {
+				b := byte(0)
+				if alive {
+					b = 1
+					population++
+				}
+				h.Write([]byte{b})
+			}
+		}
+		sum := h.Sum64()
+
+		run.Population = population
+		if population > run.Peak {
+			run.Peak = population
+		}
+		if population == 0 && run.ExtinctAt < 0 {
+			run.ExtinctAt = generation
+		}
+		if generation > 0 && run.SettledAt < 0 && (sum == hashes[0] || sum == hashes[1]) {
+			run.SettledAt = generation
+		}
+		hashes[1], hashes[0] = hashes[0], sum
+
+		if generation == s.Generations {
+			return
+		}
+		g.Step()
+	}
+}
+
+// writeExperiment writes runs as CSV with a header line.
+func writeExperiment(w io.Writer, runs []experimentRun) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"rule", "seed", "density", "population", "peak", "extinct_at", "settled_at"})
+	for _, r := range runs {
+		cw.Write([]string{
+			r.Rule,
+			strconv.FormatInt(r.Seed, 10),
+			strconv.FormatFloat(r.Density, 'g', -1, 64),
+			strconv.Itoa(r.Population),
+			strconv.Itoa(r.Peak),
+			optionalGeneration(r.ExtinctAt),
+			optionalGeneration(r.SettledAt),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// optionalGeneration leaves the column empty for -1, meaning never.
+func optionalGeneration(generation int) string {
+	if generation < 0 {
+		return ""
+	}
+	return strconv.Itoa(generation)
+}
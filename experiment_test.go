@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestExperiment runs a small grid, checking that running it in parallel
+// gives the same results in the same order, and what the CSV says.
+func TestExperiment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.json")
+	spec := `{"width": 24, "height": 24, "generations": 60,
+		"rules": ["conway", "B36/S23"], "seeds": [1, 2], "densities": [0, 0.3]}`
+	if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := loadExperiment(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runs := s.run(1)
+	if len(runs) != 8 {
+		t.Fatalf("%v runs, want 8", len(runs))
+	}
+	if parallel := s.run(4); !reflect.DeepEqual(parallel, runs) {
+		t.Errorf("in parallel:\n%+v\nwant\n%+v", parallel, runs)
+	}
+
+	var csv strings.Builder
+	if err := writeExperiment(&csv, runs); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(csv.String(), "\n")
+	if want := "rule,seed,density,population,peak,extinct_at,settled_at"; lines[0] != want {
+		t.Errorf("header %q, want %q", lines[0], want)
+	}
+	// An empty board is extinct from the start and settled one generation
+	// later.
+	if want := "B3/S23,1,0,0,0,0,1"; lines[1] != want {
+		t.Errorf("first run %q, want %q", lines[1], want)
+	}
+	if runs[1].Peak == 0 || runs[1].Rule != "B3/S23" || runs[1].Density != 0.3 {
+		t.Errorf("second run %+v, want a soup under B3/S23", runs[1])
+	}
+}
+
+func TestLoadExperimentErrors(t *testing.T) {
+	for _, spec := range []string{
+		`{"rules": ["B3/S23/X"]}`,
+		`{"densities": [1.5]}`,
+		`{"seeds": [0]}`,
+		`{"boundary": "klein"}`,
+		`{"generations": -1}`,
+	} {
+		path := filepath.Join(t.TempDir(), "spec.json")
+		if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadExperiment(path); err == nil {
+			t.Errorf("%v was accepted", spec)
+		}
+	}
+}
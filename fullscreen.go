@@ -0,0 +1,26 @@
+package main
+
+import "github.com/go-gl/glfw/v3.3/glfw"
+
+// fullscreenToggle switches a window between windowed and fullscreen on the
+// primary monitor, remembering where the window was so it goes back there.
+type fullscreenToggle struct {
+	window *glfw.Window
+
+	// x, y, width and height are the window's placement before it went
+	// fullscreen, in screen coordinates.
+	x, y, width, height int
+}
+
+func (f *fullscreenToggle) toggle() {
+	if f.window.GetMonitor() != nil {
+		f.window.SetMonitor(nil, f.x, f.y, f.width, f.height, glfw.DontCare)
+		return
+	}
+
+	f.x, f.y = f.window.GetPos()
+	f.width, f.height = f.window.GetSize()
+	monitor := glfw.GetPrimaryMonitor()
+	mode := monitor.GetVideoMode()
+	f.window.SetMonitor(monitor, 0, 0, mode.Width, mode.Height, mode.RefreshRate)
+}
@@ -1,37 +1,116 @@
 package main
 
 import (
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
 	"math/rand"
 	"time"
 )
 
 type cell struct {
-	drawable uint32
-
-	alive     bool
-	aliveNext bool
+	state     uint8
+	stateNext uint8
 
 	x int
 	y int
 }
 
+// A cell is dead, live, or (under Generations rules) one of the decaying
+// states that follow live. Only live cells count as neighbors.
 const (
-	threshold = 0.15
-	rows      = 100
-	columns   = 100
+	dead = life.Dead
+	live = life.Live
 )
 
+func (c *cell) alive() bool {
+	return c.state == live
+}
+
+// cellWrites counts writes to cells from outside the packed engine, which
+// reads the board back in when it changes. Anything that changes a cell's
+// state other than with set should count itself here too. Change it while
+// holding the simulation lock.
+var cellWrites uint64
+
+// set makes the cell live or dead right away, in this generation and the next.
+func (c *cell) set(alive bool) {
+	cellWrites++
+	c.state = dead
+	if alive {
+		c.state = live
+	}
+	c.stateNext = c.state
+}
+
+const threshold = 0.15
+
+// columns and rows are the size of the board in cells. cells[x][y] runs left
+// to right in x and bottom to top in y.
+var (
+	columns = 100
+	rows    = 100
+)
+
+// board is the grid of cells the simulation runs on.
+type board struct {
+	cells  [][]*cell
+	engine engine
+}
+
+func newBoard() *board {
+	return &board{cells: makeCells(), engine: &packedEngine{}}
+}
+
+// Step advances the board one generation under the current rule.
+func (b *board) Step() {
+	b.engine.advance(b.cells, 0)
+}
+
+// Warp advances the board 2^k generations at once, which the hashlife engine
+// can do far faster than stepping.
+func (b *board) Warp(k int) {
+	b.engine.advance(b.cells, k)
+}
+
+// population returns the number of live cells, as counted by the GPU engine
+// if it has counted this board, and by visiting every cell otherwise.
+func (b *board) population() int {
+	if gpu, ok := b.engine.(*gpuEngine); ok {
+		if n, ok := gpu.counted(); ok {
+			return n
+		}
+	}
+	return population(b.cells)
+}
+
+// seed is the random seed the starting board is made from. If it's 0 when
+// the board is made, one is picked from the clock and stored here so the
+// board can be made again.
+var seed int64
+
 func makeCells() [][]*cell {
-	rand.Seed(time.Now().UnixNano())
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rand.Seed(seed)
+
+	// --strict seeds from an integer generator, so the board comes out the
+	// same on every platform.
+	var strictAlive []bool
+	if strict {
+		strictAlive = life.Fill(seed, threshold, columns*rows)
+	}
 
-	cells := make([][]*cell, rows, rows)
+	cells := make([][]*cell, columns, columns)
 
-	for x := 0; x < rows; x++ {
-		for y := 0; y < columns; y++ {
+	for x := 0; x < columns; x++ {
+		for y := 0; y < rows; y++ {
 			c := newCell(x, y)
 
-			c.alive = rand.Float64() < threshold
-			c.aliveNext = c.alive
+			if strict {
+				c.set(strictAlive[x*rows+y])
+			} else {
+				c.set(rand.Float64() < threshold)
+			}
 
 			cells[x] = append(cells[x], c)
 		}
@@ -40,85 +119,74 @@ func makeCells() [][]*cell {
 	return cells
 }
 
-func newCell(x, y int) *cell {
-	points := make([]float32, len(square), len(square))
-	copy(points, square)
-
-	for i := 0; i < len(points); i++ {
-		var position float32
-		var size float32
-
-		switch i % 3 {
-		case 0:
-			size = 1.0 / float32(columns)
-			position = float32(x) * size
-		case 1:
-			size = 1.0 / float32(rows)
-			position = float32(y) * size
-		default:
-			continue
+// snapshot returns the alive state of every cell, indexed the same way as cells.
+func snapshot(cells [][]*cell) [][]bool {
+	alive := make([][]bool, len(cells))
+	for x := range cells {
+		alive[x] = make([]bool, len(cells[x]))
+		for y, c := range cells[x] {
+			alive[x][y] = c.alive()
 		}
+	}
+	return alive
+}
 
-		if points[i] < 0 {
-			points[i] = (position * 2) - 1
-		} else {
-			points[i] = ((position + size) * 2) - 1
+// restore sets every cell to the alive state in alive, as returned by
+// snapshot.
+func (b *board) restore(alive [][]bool) {
+	b.engine.reset()
+	for x := range b.cells {
+		for y, c := range b.cells[x] {
+			c.set(alive[x][y])
 		}
 	}
+}
 
-	return &cell{
-		drawable: makeVao(points),
+// Save appends the state of every cell, in the same order as the cells, to
+// dst[:0] and returns it, so a caller can reuse the same buffer.
+func (b *board) Save(dst []uint8) []uint8 {
+	dst = dst[:0]
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			dst = append(dst, c.state)
+		}
+	}
+	return dst
+}
+
+// Load puts back the states returned by Save.
+func (b *board) Load(src []uint8) {
+	b.engine.reset()
+	cellWrites++
+	i := 0
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			c.state, c.stateNext = src[i], src[i]
+			i++
+		}
+	}
+}
 
+func newCell(x, y int) *cell {
+	return &cell{
 		x: x,
 		y: y,
 	}
 }
 
+// checkState works out the cell's state next generation from the current
+// one, without changing what its neighbors see.
 func (c *cell) checkState(cells [][]*cell) {
-	c.alive = c.aliveNext
-	c.aliveNext = c.alive
-
-	liveCount := c.liveNeighbors(cells)
-	if c.alive {
-		// 1. Any live cell with fewer than two live neighbours dies, as if caused by underpopulation.
-		if liveCount < 2 {
-			c.aliveNext = false
-		}
-
-		// 2. Any live cell with two or three live neighbours lives on to the next generation.
-		if liveCount == 2 || liveCount == 3 {
-			c.aliveNext = true
-		}
-
-		// 3. Any live cell with more than three live neighbours dies, as if by overpopulation.
-		if liveCount > 3 {
-			c.aliveNext = false
-		}
-	} else {
-		// 4. Any dead cell with exactly three live neighbours becomes a live cell, as if by reproduction.
-		if liveCount == 3 {
-			c.aliveNext = true
-		}
-	}
+	c.stateNext = lifeTable[c.state][c.liveNeighbors(cells)]
 }
 
 // liveNeighbors returns the number of live neighbors for a cell.
 func (c *cell) liveNeighbors(cells [][]*cell) int {
 	var liveCount int
 	add := func(x, y int) {
-		// If we're at an edge, check the other side of the board.
-		if x == len(cells) {
-			x = 0
-		} else if x == -1 {
-			x = len(cells) - 1
-		}
-		if y == len(cells[x]) {
-			y = 0
-		} else if y == -1 {
-			y = len(cells[x]) - 1
-		}
-
-		if cells[x][y].alive {
+		// At an edge, the boundary mode decides what's on the other side.
+		x, y, ok := boundary.resolve(x, y, len(cells), len(cells[0]))
+		if ok && cells[x][y].alive() {
 			liveCount++
 		}
 	}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"runtime"
+)
+
+// gpuEngine runs the rule in a fragment shader, drawing each generation from
+// one texture into another and swapping them. It has a hidden window of its
+// own, so its GL context can be made current on whichever thread steps the
+// board. It handles two-state B/S rules under every boundary mode and falls
+// back to the naive engine otherwise.
+//
+// It also counts the live cells of the last generation with an occlusion
+// query, so the HUD needn't count them on the CPU every frame.
+type gpuEngine struct {
+	context *glfw.Window
+
+	prog         uint32
+	countProg    uint32
+	query        uint32
+	quad         uint32
+	textures     [2]uint32
+	framebuffers [2]uint32
+
+	columns, rows int
+	texels        []uint8
+
+	// population is the count from the last advance, and synced cellWrites
+	// just after it, to tell whether the board has changed since.
+	population int
+	synced     uint64
+}
+
+// newGPUEngine opens the engine's hidden window. Like any window, it must be
+// opened on the main thread.
+func newGPUEngine() (*gpuEngine, error) {
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	defer glfw.WindowHint(glfw.Visible, glfw.True)
+
+	context, err := glfw.CreateWindow(1, 1, title, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &gpuEngine{context: context}, nil
+}
+
+// reset does nothing, since the board is uploaded afresh every advance.
+func (e *gpuEngine) reset() {}
+
+func (e *gpuEngine) advance(cells [][]*cell, k int) {
+	if activeLtL != nil || activeRule.StateCount() > 2 {
+		naiveEngine{}.advance(cells, k)
+		return
+	}
+
+	// A context is current per thread, so stay on this one until the
+	// caller's context, if any, is current again.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	previous := glfw.GetCurrentContext()
+	e.context.MakeContextCurrent()
+	defer func() {
+		if previous != nil {
+			previous.MakeContextCurrent()
+		} else {
+			glfw.DetachCurrentContext()
+		}
+	}()
+
+	if e.prog == 0 {
+		e.setup()
+	}
+	if e.columns != len(cells) || e.rows != len(cells[0]) {
+		e.resize(len(cells), len(cells[0]))
+	}
+
+	for y := 0; y < e.rows; y++ {
+		for x := 0; x < e.columns; x++ {
+			e.texels[y*e.columns+x] = 0
+			if cells[x][y].alive() {
+				e.texels[y*e.columns+x] = 255
+			}
+		}
+	}
+	gl.BindTexture(gl.TEXTURE_2D, e.textures[0])
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int32(e.columns), int32(e.rows), gl.RED, gl.UNSIGNED_BYTE, gl.Ptr(e.texels))
+
+	wrap := int32(gl.REPEAT)
+	switch boundary {
+	case boundaryDead:
+		wrap = gl.CLAMP_TO_BORDER
+	case boundaryMirror:
+		wrap = gl.MIRRORED_REPEAT
+	}
+	for _, texture := range e.textures {
+		gl.BindTexture(gl.TEXTURE_2D, texture)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, wrap)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, wrap)
+	}
+
+	birth, survive := ruleMasks(activeRule)
+	gl.UseProgram(e.prog)
+	gl.Uniform2f(gl.GetUniformLocation(e.prog, gl.Str("u_size\x00")), float32(e.columns), float32(e.rows))
+	gl.Uniform1i(gl.GetUniformLocation(e.prog, gl.Str("u_birth\x00")), int32(birth))
+	gl.Uniform1i(gl.GetUniformLocation(e.prog, gl.Str("u_survive\x00")), int32(survive))
+	gl.Viewport(0, 0, int32(e.columns), int32(e.rows))
+	gl.BindVertexArray(e.quad)
+
+	current := 0
+	for i := 0; i < 1<<k; i++ {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, e.framebuffers[1-current])
+		gl.BindTexture(gl.TEXTURE_2D, e.textures[current])
+		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(square)/3))
+		current = 1 - current
+	}
+
+	// Every live cell of the last generation passes count.frag, so the
+	// query's sample count is the population. The draw goes to the spare
+	// framebuffer with color writes off, so nothing is changed.
+	gl.UseProgram(e.countProg)
+	gl.Uniform2f(gl.GetUniformLocation(e.countProg, gl.Str("u_size\x00")), float32(e.columns), float32(e.rows))
+	gl.BindFramebuffer(gl.FRAMEBUFFER, e.framebuffers[1-current])
+	gl.BindTexture(gl.TEXTURE_2D, e.textures[current])
+	gl.ColorMask(false, false, false, false)
+	gl.BeginQuery(gl.SAMPLES_PASSED, e.query)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(square)/3))
+	gl.EndQuery(gl.SAMPLES_PASSED)
+	gl.ColorMask(true, true, true, true)
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, e.framebuffers[current])
+	gl.ReadPixels(0, 0, int32(e.columns), int32(e.rows), gl.RED, gl.UNSIGNED_BYTE, gl.Ptr(e.texels))
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+
+	for y := 0; y < e.rows; y++ {
+		for x := 0; x < e.columns; x++ {
+			cells[x][y].set(e.texels[y*e.columns+x] > 127)
+		}
+	}
+
+	var count uint32
+	gl.GetQueryObjectuiv(e.query, gl.QUERY_RESULT, &count)
+	e.population, e.synced = int(count), cellWrites
+}
+
+// counted returns the population of the board as of the last advance, or
+// false if the board has changed since.
+func (e *gpuEngine) counted() (int, bool) {
+	return e.population, e.prog != 0 && e.synced == cellWrites
+}
+
+// setup builds the programs, the query and the quad covering the board. The
+// engine's context must be current.
+func (e *gpuEngine) setup() {
+	e.prog = gpuProgram("gpu.frag")
+	e.countProg = gpuProgram("count.frag")
+	gl.GenQueries(1, &e.query)
+
+	quad := make([]float32, len(square))
+	for i, v := range square {
+		quad[i] = v * 2
+	}
+	e.quad = makeVao(quad)
+
+	// Rows of one-byte texels aren't padded to four bytes.
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+}
+
+// gpuProgram links gpu.vert with the fragment shader called fragment.
+func gpuProgram(fragment string) uint32 {
+	vertexSource, err := shaderSource("gpu.vert")
+	if err != nil {
+		panic(err)
+	}
+	fragmentSource, err := shaderSource(fragment)
+	if err != nil {
+		panic(err)
+	}
+	vertexShader, err := compileShader(vertexSource, gl.VERTEX_SHADER)
+	if err != nil {
+		panic(err)
+	}
+	fragmentShader, err := compileShader(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		panic(err)
+	}
+
+	prog := gl.CreateProgram()
+	gl.AttachShader(prog, vertexShader)
+	gl.AttachShader(prog, fragmentShader)
+	gl.LinkProgram(prog)
+	return prog
+}
+
+// resize makes the textures and framebuffers for a board of the given size.
+// The engine's context must be current.
+func (e *gpuEngine) resize(columns, rows int) {
+	if e.textures[0] != 0 {
+		gl.DeleteFramebuffers(2, &e.framebuffers[0])
+		gl.DeleteTextures(2, &e.textures[0])
+	}
+	e.columns, e.rows = columns, rows
+	e.texels = make([]uint8, columns*rows)
+
+	gl.GenTextures(2, &e.textures[0])
+	gl.GenFramebuffers(2, &e.framebuffers[0])
+	for i, texture := range e.textures {
+		gl.BindTexture(gl.TEXTURE_2D, texture)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R8, int32(columns), int32(rows), 0, gl.RED, gl.UNSIGNED_BYTE, nil)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+
+		gl.BindFramebuffer(gl.FRAMEBUFFER, e.framebuffers[i])
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, texture, 0)
+		if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+			panic(fmt.Sprintf("gpu engine framebuffer is incomplete: 0x%x", status))
+		}
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
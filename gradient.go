@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"reflect"
+)
+
+// gradientTexels is how many colors a gradient is sampled into for the GPU,
+// which blends between neighboring ones.
+const gradientTexels = 256
+
+// gradientTexture holds the active theme's gradient as a 1D texture, which
+// color.glsl looks cells' colors up in. It's bound to texture unit 1, since
+// the board's texture takes unit 0.
+type gradientTexture struct {
+	texture uint32
+
+	// uploaded is the gradient in the texture, so it's only sent again
+	// when the theme changes.
+	uploaded gradient
+}
+
+// newGradientTexture makes the texture in the current context. Textures are
+// shared with the other windows' contexts.
+func newGradientTexture() *gradientTexture {
+	g := &gradientTexture{}
+	gl.GenTextures(1, &g.texture)
+	gl.BindTexture(gl.TEXTURE_1D, g.texture)
+	gl.TexParameteri(gl.TEXTURE_1D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_1D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_1D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	return g
+}
+
+// bind makes g's texture hold grad, and points prog, which must be in use,
+// at it.
+func (g *gradientTexture) bind(prog uint32, grad gradient) {
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_1D, g.texture)
+	if !reflect.DeepEqual(g.uploaded, grad) {
+		texels := grad.texels()
+		gl.TexImage1D(gl.TEXTURE_1D, 0, gl.RGB8, gradientTexels, 0, gl.RGB, gl.UNSIGNED_BYTE, gl.Ptr(texels))
+		g.uploaded = grad
+	}
+	gl.ActiveTexture(gl.TEXTURE0)
+
+	gl.Uniform1i(gl.GetUniformLocation(prog, gl.Str("u_gradient\x00")), 1)
+	density := int32(0)
+	if grad.density {
+		density = 1
+	}
+	gl.Uniform1i(gl.GetUniformLocation(prog, gl.Str("u_byDensity\x00")), density)
+}
+
+// texels samples the gradient evenly from one end to the other, three bytes
+// to a color.
+func (g gradient) texels() []uint8 {
+	texels := make([]uint8, 0, 3*gradientTexels)
+	for i := 0; i < gradientTexels; i++ {
+		c := g.colorAt(float64(i) / (gradientTexels - 1))
+		for _, v := range c {
+			texels = append(texels, uint8(v*255+0.5))
+		}
+	}
+	return texels
+}
@@ -0,0 +1,33 @@
+package main
+
+import "github.com/go-gl/gl/v4.1-core/gl"
+
+// gridOverlay draws lines between the cells, toggled with G, so cell
+// boundaries show when zoomed in for editing. It's drawn before the cells, so
+// they cover it.
+type gridOverlay struct {
+	// quads holds the vertex array of the quad in each window's GL context,
+	// indexed by view, as with cellRenderer.
+	quads []uint32
+}
+
+// newGridOverlay makes the overlay with its quad in the current context, which
+// should be the main window's.
+func newGridOverlay() *gridOverlay {
+	g := &gridOverlay{}
+	g.addView()
+	return g
+}
+
+// addView makes the overlay's quad for another window, whose context must be
+// current.
+func (g *gridOverlay) addView() {
+	g.quads = append(g.quads, boardQuad())
+}
+
+// draw draws the grid with prog, which must be in use.
+func (g *gridOverlay) draw(prog uint32) {
+	gl.Uniform2f(gl.GetUniformLocation(prog, gl.Str("u_cells\x00")), float32(columns), float32(rows))
+	gl.BindVertexArray(g.quads[activeView])
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(square)/3))
+}
@@ -0,0 +1,281 @@
+package main
+
+// maxHashlifeNodes bounds the memo tables. When they grow past it they are
+// dropped and rebuilt from the next board, trading speed for memory.
+const maxHashlifeNodes = 1 << 22
+
+// node is a square quadtree of 2^level cells on a side. Nodes are
+// canonical: two equal squares are always the same *node, which is what lets
+// results be memoized by pointer.
+type node struct {
+	nw, ne, sw, se *node
+	level          int
+	population     int
+}
+
+// memoKey names the result of advancing a node by 2^k generations.
+type memoKey struct {
+	n *node
+	k int
+}
+
+// hashlife is Bill Gosper's algorithm: the board is turned into a quadtree
+// of canonical nodes and the future of each node's center is memoized, so
+// repetitive patterns can be run forward by huge numbers of generations.
+//
+// Hashlife runs the board as part of an unbounded plane, and cells that
+// leave the board are dropped once the jump is done, so it only stands in for
+// the dead boundary, and only for jumps that can't reach the edge: on the
+// plane, cells that crossed the edge partway through a jump could come back
+// onto the board. A jump of 2^k generations needs every live cell to be at
+// least 2^k cells inside the edge. It also only handles two-state B/S rules
+// without B0, since it takes an empty square to stay empty. It falls back to
+// the naive engine otherwise.
+type hashlife struct {
+	deadLeaf, liveLeaf *node
+
+	nodes  map[[4]*node]*node
+	empty  []*node
+	result map[memoKey]*node
+}
+
+func newHashlife() *hashlife {
+	h := &hashlife{}
+	h.reset()
+	return h
+}
+
+func (h *hashlife) reset() {
+	h.deadLeaf = &node{}
+	h.liveLeaf = &node{population: 1}
+	h.nodes = map[[4]*node]*node{}
+	h.empty = []*node{h.deadLeaf}
+	h.result = map[memoKey]*node{}
+}
+
+// join returns the canonical node made of four quadrants of the same level.
+func (h *hashlife) join(nw, ne, sw, se *node) *node {
+	key := [4]*node{nw, ne, sw, se}
+	if n, ok := h.nodes[key]; ok {
+		return n
+	}
+	n := &node{
+		nw: nw, ne: ne, sw: sw, se: se,
+		level:      nw.level + 1,
+		population: nw.population + ne.population + sw.population + se.population,
+	}
+	h.nodes[key] = n
+	return n
+}
+
+// emptyNode returns the canonical all-dead node of the given level.
+func (h *hashlife) emptyNode(level int) *node {
+	for len(h.empty) <= level {
+		e := h.empty[len(h.empty)-1]
+		h.empty = append(h.empty, h.join(e, e, e, e))
+	}
+	return h.empty[level]
+}
+
+// centre returns a node one level up with n in the middle and dead cells
+// around it.
+func (h *hashlife) centre(n *node) *node {
+	e := h.emptyNode(n.level - 1)
+	return h.join(
+		h.join(e, e, e, n.nw),
+		h.join(e, e, n.ne, e),
+		h.join(e, n.sw, e, e),
+		h.join(n.se, e, e, e),
+	)
+}
+
+// life4x4 returns the middle 2x2 of a 4x4 node one generation on.
+func (h *hashlife) life4x4(n *node) *node {
+	// grid[x][y] with y pointing up, the same way as the board.
+	var grid [4][4]uint8
+	quads := []struct {
+		q      *node
+		x0, y0 int
+	}{{n.sw, 0, 0}, {n.se, 2, 0}, {n.nw, 0, 2}, {n.ne, 2, 2}}
+	for _, q := range quads {
+		grid[q.x0][q.y0] = uint8(q.q.sw.population)
+		grid[q.x0+1][q.y0] = uint8(q.q.se.population)
+		grid[q.x0][q.y0+1] = uint8(q.q.nw.population)
+		grid[q.x0+1][q.y0+1] = uint8(q.q.ne.population)
+	}
+
+	next := func(x, y int) *node {
+		count := 0
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				if dx != 0 || dy != 0 {
+					count += int(grid[x+dx][y+dy])
+				}
+			}
+		}
+		if lifeTable[grid[x][y]][count] == live {
+			return h.liveLeaf
+		}
+		return h.deadLeaf
+	}
+	return h.join(next(1, 2), next(2, 2), next(1, 1), next(2, 1))
+}
+
+// successor returns the middle half of n, 2^k generations on. k is capped at
+// n.level-2, the furthest the middle can be worked out from n alone.
+func (h *hashlife) successor(n *node, k int) *node {
+	if n.population == 0 {
+		return h.emptyNode(n.level - 1)
+	}
+	if n.level == 2 {
+		return h.life4x4(n)
+	}
+	if k > n.level-2 {
+		k = n.level - 2
+	}
+
+	key := memoKey{n, k}
+	if r, ok := h.result[key]; ok {
+		return r
+	}
+
+	// Nine overlapping subsquares one level down, each advanced by up to
+	// 2^(level-3) generations.
+	c1 := h.successor(h.join(n.nw.nw, n.nw.ne, n.nw.sw, n.nw.se), k)
+	c2 := h.successor(h.join(n.nw.ne, n.ne.nw, n.nw.se, n.ne.sw), k)
+	c3 := h.successor(h.join(n.ne.nw, n.ne.ne, n.ne.sw, n.ne.se), k)
+	c4 := h.successor(h.join(n.nw.sw, n.nw.se, n.sw.nw, n.sw.ne), k)
+	c5 := h.successor(h.join(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw), k)
+	c6 := h.successor(h.join(n.ne.sw, n.ne.se, n.se.nw, n.se.ne), k)
+	c7 := h.successor(h.join(n.sw.nw, n.sw.ne, n.sw.sw, n.sw.se), k)
+	c8 := h.successor(h.join(n.sw.ne, n.se.nw, n.sw.se, n.se.sw), k)
+	c9 := h.successor(h.join(n.se.nw, n.se.ne, n.se.sw, n.se.se), k)
+
+	var r *node
+	if k < n.level-2 {
+		// The nine are already far enough on; just stitch their middles.
+		r = h.join(
+			h.join(c1.se, c2.sw, c4.ne, c5.nw),
+			h.join(c2.se, c3.sw, c5.ne, c6.nw),
+			h.join(c4.se, c5.sw, c7.ne, c8.nw),
+			h.join(c5.se, c6.sw, c8.ne, c9.nw),
+		)
+	} else {
+		// Advance the four overlapping quarters of the nine again.
+		r = h.join(
+			h.successor(h.join(c1, c2, c4, c5), k),
+			h.successor(h.join(c2, c3, c5, c6), k),
+			h.successor(h.join(c4, c5, c7, c8), k),
+			h.successor(h.join(c5, c6, c8, c9), k),
+		)
+	}
+
+	h.result[key] = r
+	return r
+}
+
+// build returns the node for the square of 2^level cells whose bottom-left
+// corner is at x0, y0 on the board. Cells off the board are dead.
+func (h *hashlife) build(cells [][]*cell, level, x0, y0 int) *node {
+	size := 1 << level
+	if x0 >= len(cells) || y0 >= len(cells[0]) || x0+size <= 0 || y0+size <= 0 {
+		return h.emptyNode(level)
+	}
+	if level == 0 {
+		if cells[x0][y0].alive() {
+			return h.liveLeaf
+		}
+		return h.deadLeaf
+	}
+
+	half := size / 2
+	return h.join(
+		h.build(cells, level-1, x0, y0+half),
+		h.build(cells, level-1, x0+half, y0+half),
+		h.build(cells, level-1, x0, y0),
+		h.build(cells, level-1, x0+half, y0),
+	)
+}
+
+// write brings to life the live cells of n, whose bottom-left corner is at
+// x0, y0 on the board. Cells off the board are dropped.
+func (h *hashlife) write(cells [][]*cell, n *node, x0, y0 int) {
+	size := 1 << n.level
+	if n.population == 0 || x0 >= len(cells) || y0 >= len(cells[0]) || x0+size <= 0 || y0+size <= 0 {
+		return
+	}
+	if n.level == 0 {
+		cells[x0][y0].set(true)
+		return
+	}
+
+	half := size / 2
+	h.write(cells, n.nw, x0, y0+half)
+	h.write(cells, n.ne, x0+half, y0+half)
+	h.write(cells, n.sw, x0, y0)
+	h.write(cells, n.se, x0+half, y0)
+}
+
+func (h *hashlife) advance(cells [][]*cell, k int) {
+	birth, _ := ruleMasks(activeRule)
+	if activeLtL != nil || activeRule.StateCount() > 2 || birth&1 != 0 || boundary != boundaryDead || margin(cells) < 1<<k {
+		naiveEngine{}.advance(cells, k)
+		return
+	}
+	if len(h.nodes) > maxHashlifeNodes {
+		h.reset()
+	}
+
+	// The smallest node covering the board, with the board's middle at the
+	// node's middle so that centre and successor keep it in place.
+	level := 2
+	for 1<<level < len(cells) || 1<<level < len(cells[0]) {
+		level++
+	}
+	x0 := len(cells)/2 - 1<<(level-1)
+	y0 := len(cells[0])/2 - 1<<(level-1)
+	root := h.build(cells, level, x0, y0)
+
+	// Pad until the node is big enough to run 2^k generations, then twice
+	// more so nothing can grow out of the middle half successor returns.
+	for root.level < k+2 {
+		root = h.centre(root)
+	}
+	root = h.centre(h.centre(root))
+	result := h.successor(root, k)
+
+	cellWrites++
+	for x := range cells {
+		for _, c := range cells[x] {
+			c.state = dead
+		}
+	}
+	half := 1 << (result.level - 1)
+	h.write(cells, result, len(cells)/2-half, len(cells[0])/2-half)
+	for x := range cells {
+		for _, c := range cells[x] {
+			c.stateNext = c.state
+		}
+	}
+}
+
+// margin returns how far the live cell nearest the edge of the board is from
+// it, counting a cell on the edge as 0, or the size of the board if none is
+// alive.
+func margin(cells [][]*cell) int {
+	columns, rows := len(cells), len(cells[0])
+	nearest := columns + rows
+	for x := range cells {
+		for y, c := range cells[x] {
+			if !c.alive() {
+				continue
+			}
+			for _, d := range []int{x, columns - 1 - x, y, rows - 1 - y} {
+				if d < nearest {
+					nearest = d
+				}
+			}
+		}
+	}
+	return nearest
+}
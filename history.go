@@ -0,0 +1,131 @@
+package main
+
+import "fmt"
+
+// history is a ring buffer of the most recent generations, so the board can
+// be stepped backwards. Its memory is fixed at one byte per cell for each
+// generation it holds. A nil history holds nothing.
+type history struct {
+	states      [][]uint8
+	generations []int
+
+	next  int // where the next generation goes
+	count int
+}
+
+// newHistory returns a history holding up to size generations, or nil if size
+// is 0.
+func newHistory(size int) *history {
+	if size <= 0 {
+		return nil
+	}
+	return &history{
+		states:      make([][]uint8, size),
+		generations: make([]int, size),
+	}
+}
+
+// push records the board as it is at generation, dropping the oldest
+// generation if the history is full.
+func (h *history) push(b *board, generation int) {
+	if h == nil {
+		return
+	}
+
+	h.states[h.next] = b.Save(h.states[h.next])
+	h.generations[h.next] = generation
+	h.next = (h.next + 1) % len(h.states)
+	if h.count < len(h.states) {
+		h.count++
+	}
+}
+
+// pop restores the most recent generation recorded to the board and returns
+// its number, or false if there are none left.
+func (h *history) pop(b *board) (generation int, ok bool) {
+	if h == nil || h.count == 0 {
+		return 0, false
+	}
+
+	h.next = (h.next - 1 + len(h.states)) % len(h.states)
+	h.count--
+	b.Load(h.states[h.next])
+	return h.generations[h.next], true
+}
+
+// peek restores the generation back steps before the board's to it, 1 being
+// the most recent recorded, without forgetting any, and returns its number.
+// back must be between 1 and how many are held.
+func (h *history) peek(b *board, back int) int {
+	i := (h.next - back + len(h.states)) % len(h.states)
+	b.Load(h.states[i])
+	return h.generations[i]
+}
+
+// drop forgets the n most recent generations.
+func (h *history) drop(n int) {
+	if h == nil {
+		return
+	}
+	h.next = (h.next - n + len(h.states)) % len(h.states)
+	h.count -= n
+}
+
+// clear forgets every generation.
+func (h *history) clear() {
+	if h == nil {
+		return
+	}
+	h.count = 0
+}
+
+// scrubber moves the board back and forth through a history, like the
+// playhead of a video, without forgetting anything until it settles on a
+// generation. Moving forward past the most recent generation puts back the
+// board it started from.
+type scrubber struct {
+	// back is how many generations back it's showing, or 0 when it isn't.
+	back int
+
+	// present is the board it started from, at generation.
+	present    []uint8
+	generation int
+}
+
+// move shows the generation by steps further back than the one shown, or
+// forward if by is negative, and returns its number. It returns false if
+// there's no generation there. generation is the board's, which is kept
+// when the scrubber starts.
+func (s *scrubber) move(h *history, b *board, generation, by int) (int, bool) {
+	back := s.back + by
+	if h == nil || back < 0 || back > h.count || back == s.back {
+		return 0, false
+	}
+
+	if s.back == 0 {
+		s.present = b.Save(s.present)
+		s.generation = generation
+	}
+	s.back = back
+	if back == 0 {
+		b.Load(s.present)
+		return s.generation, true
+	}
+	return h.peek(b, back), true
+}
+
+// settle keeps the generation shown as the board's, forgetting the ones after
+// it as rewinding would. It reports whether it was scrubbing.
+func (s *scrubber) settle(h *history) bool {
+	if s.back == 0 {
+		return false
+	}
+	h.drop(s.back)
+	s.back = 0
+	return true
+}
+
+// title describes where the scrubber is for the window title.
+func (s *scrubber) title(h *history, generation int) string {
+	return fmt.Sprintf(tr("Generation %v, %v of %v back  (, and . to scrub, Space to play on from here)"), generation, s.back, h.count)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sync/atomic"
+)
+
+// generationHook runs an external program every so many generations with the
+// board on its stdin as RLE, so users can process the simulation however they
+// like. A nil generationHook does nothing.
+type generationHook struct {
+	path    string
+	every   int
+	lastRun int
+
+	// running is set while the program is going. Generations that come due
+	// in the meantime are skipped, so a slow script never queues up.
+	running int32
+}
+
+// newGenerationHook returns a hook running path every generations, or nil if
+// path is empty.
+func newGenerationHook(path string, every int) *generationHook {
+	if path == "" {
+		return nil
+	}
+	return &generationHook{path: path, every: every}
+}
+
+// rewind tells the hook the board has gone back to generation, so it counts
+// on from there rather than waiting to catch up with where it last ran.
+func (h *generationHook) rewind(generation int) {
+	if h == nil || h.lastRun <= generation {
+		return
+	}
+	h.lastRun = generation
+}
+
+// observe runs the program if at least every generations have passed since it
+// last ran. The caller must hold the simulation lock, since the board is
+// encoded before observe returns; the program itself runs in the background.
+func (h *generationHook) observe(cells [][]*cell, generation int) {
+	if h == nil || generation-h.lastRun < h.every {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&h.running, 0, 1) {
+		return
+	}
+	h.lastRun = generation
+
+	var rle bytes.Buffer
+	if err := encodeRLE(&rle, snapshot(cells), ruleName()); err != nil {
+		log.Println("encoding board for script:", err)
+		atomic.StoreInt32(&h.running, 0)
+		return
+	}
+
+	cmd := exec.Command(h.path)
+	cmd.Stdin = &rle
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("GOL_GENERATION=%d", generation),
+		fmt.Sprintf("GOL_POPULATION=%d", population(cells)),
+		fmt.Sprintf("GOL_RULE=%v", ruleName()),
+		fmt.Sprintf("GOL_WIDTH=%d", len(cells)),
+		fmt.Sprintf("GOL_HEIGHT=%d", len(cells[0])),
+	)
+
+	go func() {
+		defer atomic.StoreInt32(&h.running, 0)
+		if err := cmd.Run(); err != nil {
+			log.Println("running generation script:", err)
+		}
+	}()
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	injectQueueSize    = 256
+	injectPollInterval = time.Second
+)
+
+// injector turns lines from an external source into live cells along the
+// bottom edge of the board, so a live data feed keeps stirring the pattern.
+type injector struct {
+	lines chan string
+}
+
+// newInjector starts reading from source, which is "-" for stdin, an http(s)
+// URL that is polled, or a file path that is tailed. It returns nil if source
+// is empty.
+func newInjector(source string) (*injector, error) {
+	if source == "" {
+		return nil, nil
+	}
+
+	in := &injector{lines: make(chan string, injectQueueSize)}
+
+	switch {
+	case source == "-":
+		go in.read(os.Stdin)
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		go in.poll(source)
+	default:
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			return nil, err
+		}
+		go in.tail(f)
+	}
+
+	return in, nil
+}
+
+func (in *injector) push(line string) {
+	select {
+	case in.lines <- line:
+	default:
+		// Drop input rather than fall behind the source.
+	}
+}
+
+func (in *injector) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		in.push(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		log.Println("reading injection source:", err)
+	}
+}
+
+func (in *injector) poll(url string) {
+	for range time.Tick(injectPollInterval) {
+		resp, err := http.Get(url)
+		if err != nil {
+			log.Println("polling injection source:", err)
+			continue
+		}
+		in.read(resp.Body)
+		resp.Body.Close()
+	}
+}
+
+func (in *injector) tail(f *os.File) {
+	r := bufio.NewReader(f)
+	var partial string
+	for {
+		line, err := r.ReadString('\n')
+		partial += line
+		if err == io.EOF {
+			time.Sleep(injectPollInterval)
+			continue
+		}
+		if err != nil {
+			log.Println("tailing injection source:", err)
+			return
+		}
+		in.push(strings.TrimRight(partial, "\r\n"))
+		partial = ""
+	}
+}
+
+// apply brings every pending line to life on the bottom row without waiting
+// for more. Each byte of a line picks the column it lands in, so the same
+// input always produces the same cells.
+func (in *injector) apply(cells [][]*cell) {
+	if in == nil {
+		return
+	}
+
+	for {
+		select {
+		case line := <-in.lines:
+			for _, b := range []byte(line) {
+				cells[int(b)*len(cells)/256][0].set(true)
+			}
+		default:
+			return
+		}
+	}
+}
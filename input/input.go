@@ -0,0 +1,382 @@
+// Package input turns raw GLFW events into named actions, so the app, key
+// remapping and replays all go through one code path no matter where an event
+// came from.
+package input
+
+import "github.com/go-gl/glfw/v3.3/glfw"
+
+// Action is something the user asked the app to do, whatever key, button
+// or replay it came from.
+type Action int
+
+const (
+	None Action = iota
+	Pause
+	StepOnce
+	ZoomIn
+	ZoomOut
+	Stamp
+	ToggleDiff
+	ToggleTrails
+	ToggleGrid
+	ToggleFullscreen
+	TogglePerf
+	ToggleBloom
+	ToggleScanlines
+	ToggleCRT
+	ToggleSpacetime
+	CycleBoundary
+	CycleRule
+	OpenPalette
+	Warp
+	Reset
+	Rewind
+	SaveBoard
+
+	// ScrubBack and ScrubForward move through the generations kept for
+	// Rewind without forgetting them, until the board plays on.
+	ScrubBack
+	ScrubForward
+
+	// Copy puts the live cells of the board on the clipboard as RLE, cropped
+	// to their bounding box, for pasting into other Life programs.
+	Copy
+
+	// Paste attaches the RLE pattern on the clipboard to the cursor, to be
+	// placed with a click or ToggleCell, or dropped with Cancel.
+	Paste
+	Cancel
+
+	// Bookmark marks the current generation, and OpenBookmarks opens a menu
+	// to jump back to one.
+	Bookmark
+	OpenBookmarks
+
+	// CursorUp, CursorDown, CursorLeft and CursorRight move the keyboard
+	// cursor a cell, and ToggleCell flips the cell under it, so cells can be
+	// placed without a mouse.
+	CursorUp
+	CursorDown
+	CursorLeft
+	CursorRight
+	ToggleCell
+
+	// PanUp, PanDown, PanLeft and PanRight move the camera.
+	PanUp
+	PanDown
+	PanLeft
+	PanRight
+
+	// FitCamera frames the live cells at once, and ToggleAutoFit keeps them
+	// framed as they spread.
+	FitCamera
+	ToggleAutoFit
+
+	// PaintStart, PaintMove and PaintEnd bracket a drag with the primary
+	// mouse button.
+	PaintStart
+	PaintMove
+	PaintEnd
+
+	// DragPanStart, DragPanMove and DragPanEnd bracket a drag with the
+	// middle or right mouse button, which pans the camera.
+	DragPanStart
+	DragPanMove
+	DragPanEnd
+
+	// WheelZoom zooms about the cursor by however far the mouse wheel
+	// turned, given in the event's Scroll.
+	WheelZoom
+
+	// Track follows the object under the cursor and measures its velocity,
+	// or stops following it if there's nothing there.
+	Track
+
+	// NewTab opens another board in a tab of its own, NextTab and
+	// PreviousTab switch between them, and CloseTab closes the one shown.
+	NewTab
+	NextTab
+	PreviousTab
+	CloseTab
+
+	// ToggleHUD shows or hides the generation, population and the other
+	// readouts in the corner of the window.
+	ToggleHUD
+)
+
+var names = map[Action]string{
+	None:             "None",
+	Pause:            "Pause",
+	StepOnce:         "StepOnce",
+	ZoomIn:           "ZoomIn",
+	ZoomOut:          "ZoomOut",
+	Stamp:            "Stamp",
+	ToggleDiff:       "ToggleDiff",
+	ToggleTrails:     "ToggleTrails",
+	ToggleGrid:       "ToggleGrid",
+	ToggleFullscreen: "ToggleFullscreen",
+	TogglePerf:       "TogglePerf",
+	ToggleBloom:      "ToggleBloom",
+	ToggleScanlines:  "ToggleScanlines",
+	ToggleCRT:        "ToggleCRT",
+	ToggleSpacetime:  "ToggleSpacetime",
+	CycleBoundary:    "CycleBoundary",
+	CycleRule:        "CycleRule",
+	OpenPalette:      "OpenPalette",
+	Warp:             "Warp",
+	Reset:            "Reset",
+	Rewind:           "Rewind",
+	SaveBoard:        "SaveBoard",
+	ScrubBack:        "ScrubBack",
+	ScrubForward:     "ScrubForward",
+	Copy:             "Copy",
+	Paste:            "Paste",
+	Cancel:           "Cancel",
+	Bookmark:         "Bookmark",
+	OpenBookmarks:    "OpenBookmarks",
+	CursorUp:         "CursorUp",
+	CursorDown:       "CursorDown",
+	CursorLeft:       "CursorLeft",
+	CursorRight:      "CursorRight",
+	ToggleCell:       "ToggleCell",
+	PanUp:            "PanUp",
+	PanDown:          "PanDown",
+	PanLeft:          "PanLeft",
+	PanRight:         "PanRight",
+	FitCamera:        "FitCamera",
+	ToggleAutoFit:    "ToggleAutoFit",
+	PaintStart:       "PaintStart",
+	PaintMove:        "PaintMove",
+	PaintEnd:         "PaintEnd",
+	DragPanStart:     "DragPanStart",
+	DragPanMove:      "DragPanMove",
+	DragPanEnd:       "DragPanEnd",
+	WheelZoom:        "WheelZoom",
+	Track:            "Track",
+	NewTab:           "NewTab",
+	NextTab:          "NextTab",
+	PreviousTab:      "PreviousTab",
+	CloseTab:         "CloseTab",
+	ToggleHUD:        "ToggleHUD",
+}
+
+func (a Action) String() string {
+	if name, ok := names[a]; ok {
+		return name
+	}
+	return "Action(?)"
+}
+
+// Binding is a key along with the modifiers that must be held with it.
+type Binding struct {
+	Key  glfw.Key
+	Mods glfw.ModifierKey
+}
+
+// Key binds k with no modifiers.
+func Key(k glfw.Key) Binding {
+	return Binding{Key: k}
+}
+
+// Ctrl binds k with Control held.
+func Ctrl(k glfw.Key) Binding {
+	return Binding{Key: k, Mods: glfw.ModControl}
+}
+
+// Keymap binds keys to the actions they trigger on press.
+type Keymap map[Binding]Action
+
+// DefaultKeymap holds the bindings for the actions the app currently handles.
+var DefaultKeymap = Keymap{
+	Key(glfw.KeySpace):  Pause,
+	Key(glfw.KeyRight):  StepOnce,
+	Key(glfw.KeyLeft):   Rewind,
+	Key(glfw.KeyComma):  ScrubBack,
+	Key(glfw.KeyPeriod): ScrubForward,
+	Key(glfw.KeyR):      Reset,
+	Key(glfw.KeyD):      ToggleDiff,
+	Key(glfw.KeyT):      ToggleTrails,
+	Key(glfw.KeyG):      ToggleGrid,
+	Key(glfw.KeyF11):    ToggleFullscreen,
+	Key(glfw.KeyF1):     ToggleHUD,
+	Key(glfw.KeyF3):     TogglePerf,
+	Key(glfw.KeyF5):     ToggleBloom,
+	Key(glfw.KeyF6):     ToggleScanlines,
+	Key(glfw.KeyF7):     ToggleCRT,
+	Key(glfw.KeyH):      ToggleSpacetime,
+	Key(glfw.KeyB):      CycleBoundary,
+	Key(glfw.KeyN):      CycleRule,
+	Ctrl(glfw.KeyP):     OpenPalette,
+	Key(glfw.KeyW):      Warp,
+	Ctrl(glfw.KeyS):     SaveBoard,
+	Key(glfw.KeyP):      Stamp,
+	Ctrl(glfw.KeyC):     Copy,
+	Ctrl(glfw.KeyV):     Paste,
+
+	Key(glfw.KeyM):          Bookmark,
+	Key(glfw.KeyApostrophe): OpenBookmarks,
+
+	Key(glfw.KeyEscape): Cancel,
+
+	Key(glfw.KeyI):      CursorUp,
+	Key(glfw.KeyK):      CursorDown,
+	Key(glfw.KeyJ):      CursorLeft,
+	Key(glfw.KeyL):      CursorRight,
+	Key(glfw.KeyEnter):  ToggleCell,
+	Ctrl(glfw.KeyUp):    PanUp,
+	Ctrl(glfw.KeyDown):  PanDown,
+	Ctrl(glfw.KeyLeft):  PanLeft,
+	Ctrl(glfw.KeyRight): PanRight,
+	Key(glfw.KeyEqual):  ZoomIn,
+	Key(glfw.KeyMinus):  ZoomOut,
+	Key(glfw.KeyF):      FitCamera,
+	Ctrl(glfw.KeyF):     ToggleAutoFit,
+
+	Key(glfw.KeyV): Track,
+
+	Ctrl(glfw.KeyT):        NewTab,
+	Ctrl(glfw.KeyTab):      NextTab,
+	Ctrl(glfw.KeyPageDown): NextTab,
+	Ctrl(glfw.KeyPageUp):   PreviousTab,
+	Ctrl(glfw.KeyW):        CloseTab,
+}
+
+// repeats reports whether holding down a key bound to a keeps triggering it.
+func repeats(a Action) bool {
+	switch a {
+	case StepOnce, Rewind, ScrubBack, ScrubForward, CursorUp, CursorDown, CursorLeft, CursorRight, PanUp, PanDown, PanLeft, PanRight, ZoomIn, ZoomOut:
+		return true
+	}
+	return false
+}
+
+// boundMods are the modifiers that take part in bindings. Lock keys don't.
+const boundMods = glfw.ModShift | glfw.ModControl | glfw.ModAlt | glfw.ModSuper
+
+// Event is an action along with the cursor position, in window coordinates,
+// at the time it happened.
+type Event struct {
+	Action Action
+	X, Y   float64
+	Scroll float64 // wheel steps for WheelZoom, positive away from the user
+}
+
+// Text is keyboard input delivered while text is being captured: either a
+// typed Rune, or an editing Key such as Enter, Escape or Backspace.
+type Text struct {
+	Rune rune
+	Key  glfw.Key
+}
+
+// Mapper translates a window's callbacks into Events for a handler.
+type Mapper struct {
+	keymap  Keymap
+	handler func(Event)
+
+	x, y     float64
+	inside   bool
+	dragging bool
+	panning  bool
+
+	capture func(Text)
+}
+
+// New returns a mapper that sends handler the actions keymap binds, once it
+// has been attached to a window.
+func New(keymap Keymap, handler func(Event)) *Mapper {
+	return &Mapper{keymap: keymap, handler: handler}
+}
+
+// Attach installs the mapper's callbacks on w, replacing any already set.
+// The cursor starts wherever it is, since no enter event comes for a cursor
+// that was already over the window when it opened.
+func (m *Mapper) Attach(w *glfw.Window) {
+	m.x, m.y = w.GetCursorPos()
+	m.inside = w.GetAttrib(glfw.Hovered) == glfw.True
+
+	w.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		if m.capture != nil {
+			if action != glfw.Release && isEditingKey(key) {
+				m.capture(Text{Key: key})
+			}
+			return
+		}
+
+		if action == glfw.Release {
+			return
+		}
+		if a, ok := m.keymap[Binding{Key: key, Mods: mods & boundMods}]; ok && (action == glfw.Press || repeats(a)) {
+			m.Dispatch(a)
+		}
+	})
+
+	w.SetCharCallback(func(w *glfw.Window, char rune) {
+		if m.capture != nil {
+			m.capture(Text{Rune: char})
+		}
+	})
+
+	w.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		m.x, m.y = w.GetCursorPos()
+		switch {
+		case button == glfw.MouseButtonLeft && action == glfw.Press:
+			m.dragging = true
+			m.Dispatch(PaintStart)
+		case button == glfw.MouseButtonLeft && action == glfw.Release:
+			m.dragging = false
+			m.Dispatch(PaintEnd)
+		case (button == glfw.MouseButtonMiddle || button == glfw.MouseButtonRight) && action == glfw.Press:
+			m.panning = true
+			m.Dispatch(DragPanStart)
+		case (button == glfw.MouseButtonMiddle || button == glfw.MouseButtonRight) && action == glfw.Release:
+			m.panning = false
+			m.Dispatch(DragPanEnd)
+		}
+	})
+
+	w.SetCursorPosCallback(func(w *glfw.Window, xpos, ypos float64) {
+		m.x, m.y = xpos, ypos
+		if m.dragging {
+			m.Dispatch(PaintMove)
+		}
+		if m.panning {
+			m.Dispatch(DragPanMove)
+		}
+	})
+
+	w.SetScrollCallback(func(w *glfw.Window, xoff, yoff float64) {
+		if yoff != 0 {
+			m.handler(Event{Action: WheelZoom, X: m.x, Y: m.y, Scroll: yoff})
+		}
+	})
+
+	w.SetCursorEnterCallback(func(w *glfw.Window, entered bool) {
+		m.inside = entered
+	})
+}
+
+// Dispatch sends a at the current cursor position, as if it came from the
+// window. Replays and other backends use it to drive the app.
+func (m *Mapper) Dispatch(a Action) {
+	m.handler(Event{Action: a, X: m.x, Y: m.y})
+}
+
+// CaptureText sends all keyboard input to fn as Text instead of through the
+// keymap, until it is called again with nil.
+func (m *Mapper) CaptureText(fn func(Text)) {
+	m.capture = fn
+}
+
+func isEditingKey(key glfw.Key) bool {
+	switch key {
+	case glfw.KeyEnter, glfw.KeyKPEnter, glfw.KeyEscape, glfw.KeyBackspace, glfw.KeyUp, glfw.KeyDown, glfw.KeyTab:
+		return true
+	}
+	return false
+}
+
+// Cursor returns the last known cursor position and whether the cursor is
+// over the window.
+func (m *Mapper) Cursor() (x, y float64, inside bool) {
+	return m.x, m.y, m.inside
+}
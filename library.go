@@ -0,0 +1,97 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/jake-shasteen/golang-gl-conway-life/input"
+	"github.com/jake-shasteen/golang-gl-conway-life/pattern"
+)
+
+//go:embed library/*.rle
+var libraryFiles embed.FS
+
+// libraryPattern is a pattern bundled with the game.
+type libraryPattern struct {
+	name string
+	file string
+}
+
+// library is the bundled patterns, in the order the picker shows them.
+var library = []libraryPattern{
+	{"Glider", "library/glider.rle"},
+	{"Lightweight spaceship", "library/lwss.rle"},
+	{"Gosper glider gun", "library/gosper-gun.rle"},
+	{"R-pentomino", "library/r-pentomino.rle"},
+	{"Acorn", "library/acorn.rle"},
+	{"Pulsar", "library/pulsar.rle"},
+}
+
+// load reads the pattern. The files are built in, so a bad one is a bug.
+func (l libraryPattern) load() *pattern.Pattern {
+	f, err := libraryFiles.Open(l.file)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	p, err := pattern.ReadRLE(f)
+	if err != nil {
+		panic(fmt.Sprintf("reading %v: %v", l.file, err))
+	}
+	return p
+}
+
+// stampEdits returns the edits that bring the live cells of lines to life
+// centered on x, y. Cells that fall off the board are left out.
+func stampEdits(lines []string, x, y int) []edit {
+	patternWidth := 0
+	for _, line := range lines {
+		if len(line) > patternWidth {
+			patternWidth = len(line)
+		}
+	}
+
+	left := x - patternWidth/2
+	top := y + len(lines)/2
+	var edits []edit
+	for row, line := range lines {
+		for col, ch := range line {
+			ex, ey := left+col, top-row
+			if ch == 'O' && ex >= 0 && ex < columns && ey >= 0 && ey < rows {
+				edits = append(edits, edit{x: ex, y: ey, alive: true})
+			}
+		}
+	}
+	return edits
+}
+
+// picker chooses a pattern from the library to stamp, opened with P. Like the
+// palette, it draws itself in the window title.
+type picker struct {
+	open     bool
+	selected int
+}
+
+// handle applies one key and reports whether the picker is still open and
+// whether the selected pattern should be stamped.
+func (p *picker) handle(t input.Text) (open, stamp bool) {
+	switch t.Key {
+	case glfw.KeyEscape:
+		p.open = false
+	case glfw.KeyEnter, glfw.KeyKPEnter:
+		p.open = false
+		stamp = true
+	case glfw.KeyDown, glfw.KeyTab:
+		p.selected = (p.selected + 1) % len(library)
+	case glfw.KeyUp:
+		p.selected = (p.selected + len(library) - 1) % len(library)
+	}
+	return p.open, stamp
+}
+
+// title describes the picker's state for the window title.
+func (p *picker) title() string {
+	return fmt.Sprintf(tr("Stamp %v  (%v of %v, Up/Down to choose, Enter to place at the cursor)"),
+		tr(library[p.selected].name), p.selected+1, len(library))
+}
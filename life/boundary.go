@@ -0,0 +1,66 @@
+package life
+
+import "fmt"
+
+// Boundary decides what a cell on the edge of the board sees past it.
+type Boundary int
+
+const (
+	BoundaryWrap   Boundary = iota // the board is a torus
+	BoundaryDead                   // everything past the edge is dead
+	BoundaryMirror                 // the edge reflects the cells next to it
+)
+
+func (b Boundary) String() string {
+	switch b {
+	case BoundaryWrap:
+		return "wrap"
+	case BoundaryDead:
+		return "dead"
+	case BoundaryMirror:
+		return "mirror"
+	}
+	return "unknown"
+}
+
+// ParseBoundary returns the boundary called name.
+func ParseBoundary(name string) (Boundary, error) {
+	for _, b := range []Boundary{BoundaryWrap, BoundaryDead, BoundaryMirror} {
+		if b.String() == name {
+			return b, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown boundary %q, want wrap, dead or mirror", name)
+}
+
+// Resolve maps a neighbor position that may be off a columns by rows board to
+// the cell it stands for, or returns false if it stands for a dead cell. The
+// position may be any distance off the board, as with a Larger than Life
+// radius wider than the board itself.
+func (b Boundary) Resolve(x, y, columns, rows int) (int, int, bool) {
+	if x >= 0 && x < columns && y >= 0 && y < rows {
+		return x, y, true
+	}
+
+	switch b {
+	case BoundaryWrap:
+		return wrap(x, columns), wrap(y, rows), true
+	case BoundaryMirror:
+		return reflect(x, columns), reflect(y, rows), true
+	}
+	return 0, 0, false
+}
+
+func wrap(i, size int) int {
+	return (i%size + size) % size
+}
+
+// reflect mirrors i back onto 0..size-1 as often as it takes. Mirrored edges
+// repeat the board every 2*size cells, flipped every other time.
+func reflect(i, size int) int {
+	i = wrap(i, 2*size)
+	if i >= size {
+		return 2*size - i - 1
+	}
+	return i
+}
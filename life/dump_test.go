@@ -0,0 +1,64 @@
+package life
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	g, _ := New(Config{Width: 3, Height: 3, Boundary: "dead"})
+	g.SetCells(true, Cell{1, 0}, Cell{1, 1}, Cell{1, 2})
+	g.Step()
+	var buf bytes.Buffer
+	if err := g.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "life dump 1\nrule B3/S23\nboundary dead\nsize 3 3\ngeneration 1\n\x00\x00\x00\x01\x01\x01\x00\x00\x00"
+	if buf.String() != want {
+		t.Errorf("dump is %q, want %q", buf.String(), want)
+	}
+}
+
+// TestStrictReproducible pins the dumps of strict games so that a change in
+// how they run, or a platform that runs them differently, shows up here.
+func TestStrictReproducible(t *testing.T) {
+	for _, c := range []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{Width: 64, Height: 48, Seed: 1, Density: 0.3, Strict: true, Boundary: "dead"}, "c88abbfae48bb46c5be28df0b18604a313e9f21cf38f9b864e6dbc57f26fe913"},
+		{Config{Width: 64, Height: 48, Seed: 2, Density: 0.4, Strict: true, Rule: "highlife", Boundary: "mirror"}, "1b6006049dc86d079996d52eb0547d9e2ba13c919b7a5ac6dfb652e68ffc5550"},
+		{Config{Width: 64, Height: 48, Seed: 3, Density: 0.2, Strict: true, Rule: "briansbrain"}, "6ac713121438c49914fb907ac88292fb75337ae4fa41e21ffd5d0ed1c5df37ee"},
+		{Config{Width: 64, Height: 48, Seed: 4, Density: 0.5, Strict: true, Rule: "R2,B7..9,S6..10"}, "b823f6b5eaf9cb0fc4150d110e66c37c9c43254f112e92f28ccc03cf6825a1b6"},
+	} {
+		g, err := New(c.cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 200; i++ {
+			g.Step()
+		}
+		var buf bytes.Buffer
+		g.Dump(&buf)
+		if got := fmt.Sprintf("%x", sha256.Sum256(buf.Bytes())); got != c.want {
+			t.Errorf("%+v: dump after 200 generations hashes to %v, want %v", c.cfg, got, c.want)
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	got := Fill(42, 0.5, 16)
+	var s string
+	for _, alive := range got {
+		if alive {
+			s += "O"
+		} else {
+			s += "."
+		}
+	}
+	if want := ".OOOO.O.O.OO...O"; s != want {
+		t.Errorf("Fill(42, 0.5, 16) is %v, want %v", s, want)
+	}
+}
@@ -0,0 +1,128 @@
+package life
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// fft transforms a in place, or transforms it back if inverse is set, without
+// dividing by the length. The length must be a power of two.
+func fft(a []complex128, inverse bool) {
+	n := len(a)
+	shift := 64 - bits.TrailingZeros(uint(n))
+	for i := range a {
+		if j := int(bits.Reverse64(uint64(i)) >> uint(shift)); i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size *= 2 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even, odd := a[start+k], w*a[start+k+size/2]
+				a[start+k], a[start+k+size/2] = even+odd, even-odd
+				w *= step
+			}
+		}
+	}
+}
+
+// fft2 transforms a width by height grid in place, stored a column at a
+// time, as fft does. Both sides must be powers of two.
+func fft2(a []complex128, width, height int, inverse bool) {
+	for x := 0; x < width; x++ {
+		fft(a[x*height:(x+1)*height], inverse)
+	}
+	row := make([]complex128, width)
+	for y := 0; y < height; y++ {
+		for x := range row {
+			row[x] = a[x*height+y]
+		}
+		fft(row, inverse)
+		for x, v := range row {
+			a[x*height+y] = v
+		}
+	}
+}
+
+// nextPowerOfTwo returns the smallest power of two that is at least n.
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
+
+// kernelShape is what the transform of a neighborhood depends on.
+type kernelShape struct {
+	radius        int
+	neighborhood  Neighborhood
+	width, height int
+}
+
+// kernelSpectrum returns the transform of r's neighborhood, middle included,
+// laid out around 0, 0 on a width by height torus. It's kept for the next
+// board of the same size.
+func (r *LtLRule) kernelSpectrum(width, height int) []complex128 {
+	shape := kernelShape{r.Radius, r.Neighborhood, width, height}
+	if r.spectrum != nil && r.spectrumShape == shape {
+		return r.spectrum
+	}
+
+	k := make([]complex128, width*height)
+	for dx := -r.Radius; dx <= r.Radius; dx++ {
+		for dy := -r.Radius; dy <= r.Radius; dy++ {
+			if r.Neighborhood.contains(dx, dy, r.Radius) {
+				k[wrap(dx, width)*height+wrap(dy, height)] = 1
+			}
+		}
+	}
+	fft2(k, width, height, false)
+
+	r.spectrum, r.spectrumShape = k, shape
+	return k
+}
+
+// convolve returns the number of live cells in r's neighborhood of each cell
+// of a columns by rows board, middle included, indexed x*rows+y. It pads the
+// board by the radius through b, and multiplies the transforms of the padded
+// board and the neighborhood, so it takes the same time for any radius or
+// shape. The transforms treat the padded board as a torus, but a cell's
+// neighborhood never reaches past the padding, so nothing wraps into it.
+func (r *LtLRule) convolve(columns, rows int, b Boundary, alive func(x, y int) bool) []int {
+	pad := r.Radius
+	width, height := nextPowerOfTwo(columns+2*pad), nextPowerOfTwo(rows+2*pad)
+
+	grid := make([]complex128, width*height)
+	for i := 0; i < columns+2*pad; i++ {
+		for j := 0; j < rows+2*pad; j++ {
+			if x, y, ok := b.Resolve(i-pad, j-pad, columns, rows); ok && alive(x, y) {
+				grid[i*height+j] = 1
+			}
+		}
+	}
+	fft2(grid, width, height, false)
+	for i, k := range r.kernelSpectrum(width, height) {
+		grid[i] *= k
+	}
+	fft2(grid, width, height, true)
+
+	// The counts are whole numbers, and rounding drops the transforms'
+	// floating point error, which is far smaller than a half.
+	scale := float64(width * height)
+	counts := make([]int, columns*rows)
+	for x := 0; x < columns; x++ {
+		for y := 0; y < rows; y++ {
+			counts[x*rows+y] = int(math.Round(real(grid[(x+pad)*height+y+pad]) / scale))
+		}
+	}
+	return counts
+}
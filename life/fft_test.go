@@ -0,0 +1,94 @@
+package life
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestConvolve checks the counts from convolve against counting each
+// neighborhood cell by cell, on boards that aren't powers of two and with
+// radii reaching past the board's edge.
+func TestConvolve(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, size := range [][2]int{{13, 7}, {32, 32}, {5, 9}} {
+		columns, rows := size[0], size[1]
+		board := make([]bool, columns*rows)
+		for i := range board {
+			board[i] = rng.Intn(3) == 0
+		}
+		alive := func(x, y int) bool { return board[x*rows+y] }
+
+		for _, n := range []Neighborhood{Moore, VonNeumann, Circular} {
+			for _, radius := range []int{1, 3, 6} {
+				for _, b := range []Boundary{BoundaryWrap, BoundaryDead, BoundaryMirror} {
+					r := &LtLRule{Radius: radius, Neighborhood: n}
+					counts := r.convolve(columns, rows, b, alive)
+					for x := 0; x < columns; x++ {
+						for y := 0; y < rows; y++ {
+							want := 0
+							for dx := -radius; dx <= radius; dx++ {
+								for dy := -radius; dy <= radius; dy++ {
+									if nx, ny, ok := b.Resolve(x+dx, y+dy, columns, rows); ok && n.contains(dx, dy, radius) && alive(nx, ny) {
+										want++
+									}
+								}
+							}
+							if got := counts[x*rows+y]; got != want {
+								t.Fatalf("%vx%v %v R%v %v: %v,%v counts %v, want %v", columns, rows, n, radius, b, x, y, got, want)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestStepNeighborhoods checks that the other neighborhoods are read and
+// written back, and that the Moore neighborhood steps the same through
+// convolve as through the summed-area table.
+func TestStepNeighborhoods(t *testing.T) {
+	for rule, want := range map[string]string{
+		"R5,B34..45,S33..57,NN":   "R5,C0,M0,S33..57,B34..45,NN",
+		"R7,C0,M1,S2..9,B3..4,NC": "R7,C0,M1,S2..9,B3..4,NC",
+	} {
+		r, err := ParseLtL(rule)
+		if err != nil {
+			t.Errorf("%v: %v", rule, err)
+			continue
+		}
+		if r.String() != want {
+			t.Errorf("%v reads as %v, want %v", rule, r, want)
+		}
+	}
+	if _, err := ParseLtL("R5,B34..45,S33..57,NH"); err == nil {
+		t.Errorf("the hexagonal neighborhood NH was accepted")
+	}
+
+	r, _ := ParseLtL("R4,B14..19,S14..24")
+	const columns, rows = 30, 20
+	rng := rand.New(rand.NewSource(2))
+	board := make([]bool, columns*rows)
+	for i := range board {
+		board[i] = rng.Intn(2) == 0
+	}
+	alive := func(x, y int) bool { return board[x*rows+y] }
+
+	box := make([]bool, columns*rows)
+	r.Step(columns, rows, BoundaryMirror, alive, func(x, y int, alive bool) { box[x*rows+y] = alive })
+
+	counts := r.convolve(columns, rows, BoundaryMirror, alive)
+	for x := 0; x < columns; x++ {
+		for y := 0; y < rows; y++ {
+			n := counts[x*rows+y]
+			want := n >= r.BirthMin && n <= r.BirthMax
+			if alive(x, y) {
+				n--
+				want = n >= r.SurviveMin && n <= r.SurviveMax
+			}
+			if box[x*rows+y] != want {
+				t.Fatalf("%v,%v: the summed-area table says %v, convolve %v", x, y, box[x*rows+y], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Package life runs Conway's Game of Life, and the other Life-like,
+// Generations and Larger than Life rules the game supports, in memory, for Go programs that want
+// the simulation without a window or a main loop.
+//
+// The API follows semantic versioning as given by Version: within a major
+// version, nothing exported here is removed or changes meaning.
+package life
+
+import (
+	"fmt"
+	"github.com/jake-shasteen/golang-gl-conway-life/pattern"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Version is the version of this API.
+const Version = "1.3.0"
+
+// Config describes a new game.
+type Config struct {
+	// Width and Height are the size of the board in cells.
+	Width, Height int
+
+	// Rule is a rulestring in B/S notation, such as "B36/S23", or with a
+	// state count for a Generations rule, such as "B2/S/C3", or one of the
+	// Presets by name, or a Larger than Life rule such as
+	// "R5,B34..45,S33..57". Empty means Conway's B3/S23.
+	Rule string
+
+	// Boundary is what's past the edge of the board: "wrap" (the default),
+	// "dead" or "mirror".
+	Boundary string
+
+	// Seed, if not 0, fills the board at random with live cells making up
+	// Density of it, the same way for the same seed.
+	Seed    int64
+	Density float64
+
+	// Strict seeds the board with Fill instead of math/rand, whose floats
+	// are all that could make two platforms disagree, so a game's Dump is
+	// the same bytes everywhere. StepFor depends on the clock, so strict
+	// runs should count generations with Step.
+	Strict bool
+}
+
+// Cell is a position on the board, with x running right and y down from the
+// top left corner.
+type Cell struct {
+	X, Y int
+}
+
+// Format is a way Render can write the board.
+type Format int
+
+const (
+	// Text writes a line per row, top first, with O for a live cell and .
+	// for anything else.
+	Text Format = iota
+
+	// RLE writes the board in Golly's run-length encoded format.
+	RLE
+)
+
+// Game is a board and the rule it runs under. It is not safe for concurrent
+// use.
+type Game struct {
+	width, height int
+	rule          string
+	boundary      Boundary
+	table         Table
+	ltl           *LtLRule
+	generation    int
+
+	// stepCost is a running estimate of how long one Step takes, used by
+	// StepFor to avoid starting a generation it can't finish in time.
+	stepCost time.Duration
+
+	// cells and next hold each cell's state, row by row. 0 is dead, 1 is
+	// alive, and higher states are decaying under a Generations rule.
+	cells, next []uint8
+}
+
+// New returns a game as described by cfg.
+func New(cfg Config) (*Game, error) {
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("board size %vx%v should be positive", cfg.Width, cfg.Height)
+	}
+	if cfg.Rule == "" {
+		cfg.Rule = Conway.String()
+	}
+	g := &Game{
+		width:  cfg.Width,
+		height: cfg.Height,
+		cells:  make([]uint8, cfg.Width*cfg.Height),
+		next:   make([]uint8, cfg.Width*cfg.Height),
+	}
+	if cfg.Boundary != "" {
+		b, err := ParseBoundary(cfg.Boundary)
+		if err != nil {
+			return nil, err
+		}
+		g.boundary = b
+	}
+	if r, err := LookupRule(cfg.Rule); err == nil {
+		g.rule, g.table = r.String(), r.Compile()
+	} else if ltl, ltlErr := ParseLtL(cfg.Rule); ltlErr == nil {
+		g.rule, g.ltl = ltl.String(), ltl
+	} else {
+		return nil, err
+	}
+
+	if cfg.Seed != 0 && cfg.Strict {
+		for i, alive := range Fill(cfg.Seed, cfg.Density, len(g.cells)) {
+			if alive {
+				g.cells[i] = Live
+			}
+		}
+	} else if cfg.Seed != 0 {
+		rng := rand.New(rand.NewSource(cfg.Seed))
+		for i := range g.cells {
+			if rng.Float64() < cfg.Density {
+				g.cells[i] = Live
+			}
+		}
+	}
+	return g, nil
+}
+
+// Size returns the width and height of the board.
+func (g *Game) Size() (width, height int) {
+	return g.width, g.height
+}
+
+// Generation returns how many times Step has run.
+func (g *Game) Generation() int {
+	return g.generation
+}
+
+// Rule returns the rulestring the game runs under, with a preset spelled out.
+func (g *Game) Rule() string {
+	return g.rule
+}
+
+// Step advances the board one generation.
+func (g *Game) Step() {
+	if g.ltl != nil {
+		g.ltl.Step(g.width, g.height, g.boundary,
+			func(x, y int) bool { return g.cells[y*g.width+x] == Live },
+			func(x, y int, alive bool) {
+				g.next[y*g.width+x] = Dead
+				if alive {
+					g.next[y*g.width+x] = Live
+				}
+			})
+		g.cells, g.next = g.next, g.cells
+		g.generation++
+		return
+	}
+
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			i := y*g.width + x
+			g.next[i] = g.table[g.cells[i]][g.liveNeighbors(x, y)]
+		}
+	}
+	g.cells, g.next = g.next, g.cells
+	g.generation++
+}
+
+// StepFor advances the board as many generations as fit in budget and returns
+// how many it ran, so a host application can run the simulation within its
+// own frame budget. It won't start a generation it expects to overrun the
+// budget, so it returns 0 when the budget is smaller than a single step.
+func (g *Game) StepFor(budget time.Duration) (generations int) {
+	start := time.Now()
+	for {
+		elapsed := time.Since(start)
+		if elapsed+g.stepCost > budget {
+			return generations
+		}
+
+		t := time.Now()
+		g.Step()
+		generations++
+
+		// Weight recent steps more heavily, since cost follows population.
+		g.stepCost = (3*g.stepCost + time.Since(t)) / 4
+	}
+}
+
+func (g *Game) liveNeighbors(x, y int) int {
+	n := 0
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			if nx, ny, ok := g.boundary.Resolve(x+dx, y+dy, g.width, g.height); ok && g.cells[ny*g.width+nx] == Live {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// SetCells makes cells alive or dead. It changes nothing if any of them is
+// off the board.
+func (g *Game) SetCells(alive bool, cells ...Cell) error {
+	for _, c := range cells {
+		if c.X < 0 || c.X >= g.width || c.Y < 0 || c.Y >= g.height {
+			return fmt.Errorf("cell %v,%v is off the %vx%v board", c.X, c.Y, g.width, g.height)
+		}
+	}
+
+	state := Dead
+	if alive {
+		state = Live
+	}
+	for _, c := range cells {
+		g.cells[c.Y*g.width+c.X] = state
+	}
+	return nil
+}
+
+// Snapshot returns which cells are alive, indexed [y][x]. Decaying cells under
+// a Generations rule count as dead. The caller may keep and change it.
+func (g *Game) Snapshot() [][]bool {
+	alive := make([][]bool, g.height)
+	for y := range alive {
+		alive[y] = make([]bool, g.width)
+		for x := range alive[y] {
+			alive[y][x] = g.cells[y*g.width+x] == Live
+		}
+	}
+	return alive
+}
+
+// Render writes the board to w in format.
+func (g *Game) Render(w io.Writer, format Format) error {
+	p := &pattern.Pattern{Rule: g.rule}
+	for _, row := range g.Snapshot() {
+		var sb strings.Builder
+		for _, alive := range row {
+			if alive {
+				sb.WriteByte('O')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		p.Rows = append(p.Rows, sb.String())
+	}
+
+	switch format {
+	case Text:
+		_, err := io.WriteString(w, strings.Join(p.Rows, "\n")+"\n")
+		return err
+	case RLE:
+		return p.WriteRLE(w)
+	}
+	return fmt.Errorf("unknown format %d", format)
+}
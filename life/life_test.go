@@ -0,0 +1,135 @@
+package life
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	for _, cfg := range []Config{
+		{Width: 0, Height: 5},
+		{Width: 5, Height: 5, Rule: "B3/S23/X"},
+		{Width: 5, Height: 5, Boundary: "klein"},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", cfg)
+		}
+	}
+
+	for rule, want := range map[string]string{
+		"":                   "B3/S23",
+		"b36/s23":            "B36/S23",
+		"highlife":           "B36/S23",
+		"briansbrain":        "B2/S/C3",
+		"R5,B34..45,S33..57": "R5,C0,M0,S33..57,B34..45,NM",
+	} {
+		g, err := New(Config{Width: 5, Height: 5, Rule: rule})
+		if err != nil {
+			t.Errorf("New with rule %q: %v", rule, err)
+			continue
+		}
+		if g.Rule() != want {
+			t.Errorf("New with rule %q runs %q, want %q", rule, g.Rule(), want)
+		}
+	}
+
+	a, _ := New(Config{Width: 20, Height: 20, Seed: 7, Density: 0.5})
+	b, _ := New(Config{Width: 20, Height: 20, Seed: 7, Density: 0.5})
+	if render(t, a, Text) != render(t, b, Text) {
+		t.Errorf("the same seed filled the board differently")
+	}
+}
+
+func TestStepBlinker(t *testing.T) {
+	for _, boundary := range []string{"wrap", "dead", "mirror"} {
+		g, _ := New(Config{Width: 5, Height: 5, Boundary: boundary})
+		g.SetCells(true, Cell{1, 2}, Cell{2, 2}, Cell{3, 2})
+
+		g.Step()
+		want := ".....\n..O..\n..O..\n..O..\n.....\n"
+		if got := render(t, g, Text); got != want {
+			t.Errorf("%v: blinker after one step:\n%vwant\n%v", boundary, got, want)
+		}
+		g.Step()
+		want = ".....\n.....\n.OOO.\n.....\n.....\n"
+		if got := render(t, g, Text); got != want {
+			t.Errorf("%v: blinker after two steps:\n%vwant\n%v", boundary, got, want)
+		}
+		if g.Generation() != 2 {
+			t.Errorf("%v: generation %v, want 2", boundary, g.Generation())
+		}
+	}
+}
+
+func TestStepGlider(t *testing.T) {
+	// On a wrapping board a glider moves one cell diagonally every four
+	// generations, and comes back where it started after 4*size.
+	g, _ := New(Config{Width: 8, Height: 8})
+	g.SetCells(true, Cell{1, 0}, Cell{2, 1}, Cell{0, 2}, Cell{1, 2}, Cell{2, 2})
+	start := render(t, g, Text)
+
+	for i := 0; i < 4; i++ {
+		g.Step()
+	}
+	moved, _ := New(Config{Width: 8, Height: 8})
+	moved.SetCells(true, Cell{2, 1}, Cell{3, 2}, Cell{1, 3}, Cell{2, 3}, Cell{3, 3})
+	if got, want := render(t, g, Text), render(t, moved, Text); got != want {
+		t.Errorf("glider after four steps:\n%vwant\n%v", got, want)
+	}
+
+	for i := 4; i < 32; i++ {
+		g.Step()
+	}
+	if got := render(t, g, Text); got != start {
+		t.Errorf("glider didn't come back round the board:\n%vwant\n%v", got, start)
+	}
+}
+
+func TestSetCells(t *testing.T) {
+	g, _ := New(Config{Width: 3, Height: 2})
+	if err := g.SetCells(true, Cell{0, 0}, Cell{2, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.SetCells(true, Cell{1, 0}, Cell{3, 0}); err == nil {
+		t.Errorf("SetCells off the board succeeded")
+	}
+	if err := g.SetCells(false, Cell{2, 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]bool{{true, false, false}, {false, false, false}}
+	got := g.Snapshot()
+	for y := range want {
+		for x := range want[y] {
+			if got[y][x] != want[y][x] {
+				t.Errorf("cell %v,%v is %v, want %v", x, y, got[y][x], want[y][x])
+			}
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	g, _ := New(Config{Width: 4, Height: 3, Rule: "highlife"})
+	g.SetCells(true, Cell{0, 0}, Cell{1, 0}, Cell{3, 2})
+
+	if got, want := render(t, g, Text), "OO..\n....\n...O\n"; got != want {
+		t.Errorf("Text:\n%vwant\n%v", got, want)
+	}
+	if got, want := render(t, g, RLE), "x = 4, y = 3, rule = B36/S23\n2o2$3bo!\n"; got != want {
+		t.Errorf("RLE: %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := g.Render(&buf, Format(99)); err == nil {
+		t.Errorf("Render with an unknown format succeeded")
+	}
+}
+
+func render(t *testing.T, g *Game, format Format) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := g.Render(&buf, format); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,203 @@
+package life
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LtLRule is a Larger than Life rule: like a B/S rule, but neighbors are
+// counted over a neighborhood out to Radius and birth and survival are ranges.
+type LtLRule struct {
+	Radius                 int
+	BirthMin, BirthMax     int
+	SurviveMin, SurviveMax int
+	Middle                 bool // whether a cell counts itself
+	Neighborhood           Neighborhood
+
+	// spectrum is the neighborhood's transform as last used by convolve.
+	spectrum      []complex128
+	spectrumShape kernelShape
+}
+
+// Neighborhood is the shape an LtLRule counts neighbors over.
+type Neighborhood int
+
+const (
+	Moore      Neighborhood = iota // the square, NM
+	VonNeumann                     // the diamond |dx|+|dy| <= radius, NN
+	Circular                       // the disc within radius+1/2 of the middle, NC
+)
+
+func (n Neighborhood) String() string {
+	switch n {
+	case VonNeumann:
+		return "NN"
+	case Circular:
+		return "NC"
+	}
+	return "NM"
+}
+
+// contains reports whether the cell dx, dy from the middle is in the
+// neighborhood out to radius.
+func (n Neighborhood) contains(dx, dy, radius int) bool {
+	switch n {
+	case VonNeumann:
+		return abs(dx)+abs(dy) <= radius
+	case Circular:
+		// dx²+dy² <= (radius+1/2)², in whole numbers.
+		return dx*dx+dy*dy <= radius*radius+radius
+	}
+	return abs(dx) <= radius && abs(dy) <= radius
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// ParseLtL reads a rule in Golly's Larger than Life notation, for example
+// "R5,B34..45,S33..57" or "R5,C0,M1,S34..58,B34..45,NC". Only two-state rules
+// are supported, over the Moore (NM), von Neumann (NN) or circular (NC)
+// neighborhood.
+func ParseLtL(s string) (*LtLRule, error) {
+	r := &LtLRule{}
+	var sawR, sawB, sawS bool
+
+	for _, part := range strings.Split(strings.ToUpper(strings.TrimSpace(s)), ",") {
+		if part == "" {
+			continue
+		}
+
+		var err error
+		switch part[0] {
+		case 'R':
+			_, err = fmt.Sscanf(part[1:], "%d", &r.Radius)
+			sawR = err == nil && r.Radius >= 1
+		case 'B':
+			r.BirthMin, r.BirthMax, err = parseRange(part[1:])
+			sawB = err == nil
+		case 'S':
+			r.SurviveMin, r.SurviveMax, err = parseRange(part[1:])
+			sawS = err == nil
+		case 'M':
+			r.Middle = part == "M1"
+		case 'C':
+			var states int
+			_, err = fmt.Sscanf(part[1:], "%d", &states)
+			if err == nil && states > 2 {
+				err = fmt.Errorf("only two-state rules are supported")
+			}
+		case 'N':
+			switch part {
+			case "NM":
+				r.Neighborhood = Moore
+			case "NN":
+				r.Neighborhood = VonNeumann
+			case "NC":
+				r.Neighborhood = Circular
+			default:
+				err = fmt.Errorf("only the Moore (NM), von Neumann (NN) and circular (NC) neighborhoods are supported")
+			}
+		default:
+			err = fmt.Errorf("unknown part")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("LtL rule %q: %q: %v", s, part, err)
+		}
+	}
+
+	if !sawR || !sawB || !sawS {
+		return nil, fmt.Errorf("LtL rule %q needs a radius of at least 1 and B and S ranges, e.g. R5,B34..45,S33..57", s)
+	}
+	return r, nil
+}
+
+// parseRange reads "34..45", or a single count "34".
+func parseRange(s string) (int, int, error) {
+	var min, max int
+	if strings.Contains(s, "..") {
+		if _, err := fmt.Sscanf(s, "%d..%d", &min, &max); err != nil {
+			return 0, 0, err
+		}
+	} else {
+		if _, err := fmt.Sscanf(s, "%d", &min); err != nil {
+			return 0, 0, err
+		}
+		max = min
+	}
+	if min > max {
+		return 0, 0, fmt.Errorf("range is backwards")
+	}
+	return min, max, nil
+}
+
+func (r *LtLRule) String() string {
+	m := 0
+	if r.Middle {
+		m = 1
+	}
+	return fmt.Sprintf("R%d,C0,M%d,S%d..%d,B%d..%d,%v", r.Radius, m, r.SurviveMin, r.SurviveMax, r.BirthMin, r.BirthMax, r.Neighborhood)
+}
+
+// Step works out the next generation of a columns by rows board, reading
+// which cells are alive through alive and handing each cell's next state to
+// set. Cells past the edge are filled in through b, so every boundary works.
+//
+// Over the Moore neighborhood, neighbor counts come from a summed-area table
+// over the board padded by the radius, so each count is four lookups however
+// large the radius is. Other neighborhoods aren't squares, so their counts
+// come from convolve instead, which also takes the same time at any radius.
+func (r *LtLRule) Step(columns, rows int, b Boundary, alive func(x, y int) bool, set func(x, y int, alive bool)) {
+	var count func(x, y int) int
+	if r.Neighborhood == Moore {
+		count = r.boxCounts(columns, rows, b, alive)
+	} else {
+		counts := r.convolve(columns, rows, b, alive)
+		count = func(x, y int) int { return counts[x*rows+y] }
+	}
+
+	for x := 0; x < columns; x++ {
+		for y := 0; y < rows; y++ {
+			n := count(x, y)
+			if alive(x, y) {
+				if !r.Middle {
+					n--
+				}
+				set(x, y, n >= r.SurviveMin && n <= r.SurviveMax)
+			} else {
+				set(x, y, n >= r.BirthMin && n <= r.BirthMax)
+			}
+		}
+	}
+}
+
+// boxCounts returns the number of live cells in the square out to the radius
+// around x, y, middle included, from a summed-area table.
+func (r *LtLRule) boxCounts(columns, rows int, b Boundary, alive func(x, y int) bool) func(x, y int) int {
+	pad := r.Radius
+	w, h := columns+2*pad, rows+2*pad
+
+	// sat[i][j] is the number of live cells in padded columns < i and rows < j.
+	sat := make([][]int32, w+1)
+	for i := range sat {
+		sat[i] = make([]int32, h+1)
+	}
+	for i := 0; i < w; i++ {
+		for j := 0; j < h; j++ {
+			var v int32
+			if x, y, ok := b.Resolve(i-pad, j-pad, columns, rows); ok && alive(x, y) {
+				v = 1
+			}
+			sat[i+1][j+1] = v + sat[i][j+1] + sat[i+1][j] - sat[i][j]
+		}
+	}
+
+	size := 2*r.Radius + 1
+	return func(x, y int) int {
+		// The square around x, y spans padded columns x..x+2*radius.
+		return int(sat[x+size][y+size] - sat[x][y+size] - sat[x+size][y] + sat[x][y])
+	}
+}
@@ -0,0 +1,167 @@
+package life
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A cell is Dead, Live, or (under Generations rules) one of the decaying
+// states that follow Live. Only live cells count as neighbors.
+const (
+	Dead uint8 = 0
+	Live uint8 = 1
+)
+
+// Rule is a birth/survival rule over the Moore neighborhood, listing the live
+// neighbor counts for which a dead cell is born and a live cell survives.
+// Generations rules have more than two states: a live cell that doesn't
+// survive decays through states 2, 3, ... before it is dead and can be born
+// again.
+type Rule struct {
+	Birth   []int
+	Survive []int
+	States  int // 2 for Life-like rules; 0 means 2
+}
+
+// Conway is B3/S23:
+// 1. Any live cell with fewer than two live neighbours dies, as if caused by underpopulation.
+// 2. Any live cell with two or three live neighbours lives on to the next generation.
+// 3. Any live cell with more than three live neighbours dies, as if by overpopulation.
+// 4. Any dead cell with exactly three live neighbours becomes a live cell, as if by reproduction.
+var Conway = Rule{Birth: []int{3}, Survive: []int{2, 3}}
+
+// Preset is a well-known rule that can be picked by name.
+type Preset struct {
+	Name string
+	Rule Rule
+}
+
+// Presets are the rules LookupRule knows by name, in the order the game's
+// cycle key steps through them.
+var Presets = []Preset{
+	{"conway", Conway},
+	{"highlife", Rule{Birth: []int{3, 6}, Survive: []int{2, 3}}},
+	{"seeds", Rule{Birth: []int{2}}},
+	{"daynight", Rule{Birth: []int{3, 6, 7, 8}, Survive: []int{3, 4, 6, 7, 8}}},
+	{"lifewithoutdeath", Rule{Birth: []int{3}, Survive: []int{0, 1, 2, 3, 4, 5, 6, 7, 8}}},
+	{"briansbrain", Rule{Birth: []int{2}, States: 3}},
+	{"starwars", Rule{Birth: []int{2}, Survive: []int{3, 4, 5}, States: 4}},
+}
+
+// LookupRule returns the preset called name, or else parses name as a
+// rulestring.
+func LookupRule(name string) (Rule, error) {
+	for _, p := range Presets {
+		if strings.EqualFold(p.Name, name) {
+			return p.Rule, nil
+		}
+	}
+	return ParseRule(name)
+}
+
+// ParseRule reads a rulestring in B/S notation such as "B36/S23". The halves
+// may come in either order and either case, and either may be empty, as in
+// "B2/S" for Seeds. A third part such as "C3" (or "G3") makes it a
+// Generations rule with that many states, as in "B2/S/C3" for Brian's Brain.
+func ParseRule(s string) (Rule, error) {
+	parts := strings.Split(strings.ToUpper(strings.TrimSpace(s)), "/")
+	if len(parts) != 2 && len(parts) != 3 {
+		return Rule{}, fmt.Errorf("rule %q should look like B3/S23 or B2/S/C3", s)
+	}
+
+	var r Rule
+	var sawB, sawS bool
+	for _, part := range parts {
+		if part == "" {
+			return Rule{}, fmt.Errorf("rule %q has an empty half", s)
+		}
+
+		var counts *[]int
+		switch part[0] {
+		case 'B':
+			counts, sawB = &r.Birth, true
+		case 'S':
+			counts, sawS = &r.Survive, true
+		case 'C', 'G':
+			if _, err := fmt.Sscanf(part[1:], "%d", &r.States); err != nil || r.States < 2 || r.States > 256 {
+				return Rule{}, fmt.Errorf("rule %q: %q should give 2 to 256 states", s, part)
+			}
+			continue
+		default:
+			return Rule{}, fmt.Errorf("rule %q: %q should start with B, S or C", s, part)
+		}
+
+		for _, d := range part[1:] {
+			if d < '0' || d > '8' {
+				return Rule{}, fmt.Errorf("rule %q: neighbor count %q is not 0-8", s, d)
+			}
+			*counts = append(*counts, int(d-'0'))
+		}
+	}
+	if !sawB || !sawS {
+		return Rule{}, fmt.Errorf("rule %q needs one B half and one S half", s)
+	}
+
+	return r, nil
+}
+
+func (r Rule) String() string {
+	var sb strings.Builder
+	sb.WriteString("B")
+	for _, n := range r.Birth {
+		fmt.Fprint(&sb, n)
+	}
+	sb.WriteString("/S")
+	for _, n := range r.Survive {
+		fmt.Fprint(&sb, n)
+	}
+	if r.StateCount() > 2 {
+		fmt.Fprintf(&sb, "/C%d", r.States)
+	}
+	return sb.String()
+}
+
+// StateCount returns how many states a cell can be in under r.
+func (r Rule) StateCount() int {
+	if r.States < 2 {
+		return 2
+	}
+	return r.States
+}
+
+// Table is a rule compiled into a lookup table: Table[s][n] is the next state
+// of a cell in state s with n live neighbors. It covers every state a cell
+// could hold, so cells left decaying by a previous rule just die.
+type Table [256][9]uint8
+
+// Compile works out r's Table, so stepping a cell is one lookup instead of a
+// search of the birth and survival counts.
+func (r Rule) Compile() Table {
+	states := r.StateCount()
+	var t Table
+
+	for _, n := range r.Birth {
+		t[Dead][n] = Live
+	}
+
+	// A live cell that doesn't survive starts decaying, or dies outright
+	// under a two-state rule.
+	var decay uint8
+	if states > 2 {
+		decay = 2
+	}
+	for n := range t[Live] {
+		t[Live][n] = decay
+	}
+	for _, n := range r.Survive {
+		t[Live][n] = Live
+	}
+
+	for s := 2; s < states; s++ {
+		for n := range t[s] {
+			t[s][n] = uint8((s + 1) % states)
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,92 @@
+package life
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	conway := Conway.Compile()
+	for n := 0; n <= 8; n++ {
+		wantBorn, wantSurvives := Dead, Dead
+		if n == 3 {
+			wantBorn = Live
+		}
+		if n == 2 || n == 3 {
+			wantSurvives = Live
+		}
+		if conway[Dead][n] != wantBorn {
+			t.Errorf("B3/S23: dead cell with %d neighbors becomes %d, want %d", n, conway[Dead][n], wantBorn)
+		}
+		if conway[Live][n] != wantSurvives {
+			t.Errorf("B3/S23: live cell with %d neighbors becomes %d, want %d", n, conway[Live][n], wantSurvives)
+		}
+	}
+
+	// Under Brian's Brain a live cell always decays, and a decaying one dies.
+	r, err := LookupRule("briansbrain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	brain := r.Compile()
+	for n := 0; n <= 8; n++ {
+		if brain[Live][n] != 2 || brain[2][n] != Dead {
+			t.Errorf("B2/S/C3: with %d neighbors live goes to %d and 2 to %d, want 2 and 0", n, brain[Live][n], brain[2][n])
+		}
+	}
+
+	// States left over from an earlier rule with more of them just die.
+	if conway[5][3] != Dead {
+		t.Errorf("B3/S23: state 5 goes to %d, want 0", conway[5][3])
+	}
+}
+
+// BenchmarkStepTable and BenchmarkStepInterpreted step the same soup, once
+// with the compiled table and once checking the rule's counts for each cell.
+func BenchmarkStepTable(b *testing.B) {
+	g := benchmarkGame(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.Step()
+	}
+}
+
+func BenchmarkStepInterpreted(b *testing.B) {
+	g := benchmarkGame(b)
+	r := Conway
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < g.height; y++ {
+			for x := 0; x < g.width; x++ {
+				j := y*g.width + x
+				counts := r.Birth
+				if g.cells[j] == Live {
+					counts = r.Survive
+				}
+				g.next[j] = Dead
+				n := g.liveNeighbors(x, y)
+				for _, c := range counts {
+					if c == n {
+						g.next[j] = Live
+						break
+					}
+				}
+			}
+		}
+		g.cells, g.next = g.next, g.cells
+	}
+}
+
+func benchmarkGame(b *testing.B) *Game {
+	g, err := New(Config{Width: 256, Height: 256})
+	if err != nil {
+		b.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := range g.cells {
+		if rng.Float64() < 0.3 {
+			g.cells[i] = Live
+		}
+	}
+	return g
+}
@@ -0,0 +1,19 @@
+package main
+
+import "github.com/jake-shasteen/golang-gl-conway-life/life"
+
+// activeLtL, when set, replaces the B/S rule for stepping. Change it while
+// holding the simulation lock.
+var activeLtL *life.LtLRule
+
+// stepLtL advances cells one generation under r.
+func stepLtL(r *life.LtLRule, cells [][]*cell) {
+	r.Step(len(cells), len(cells[0]), life.Boundary(boundary),
+		func(x, y int) bool { return cells[x][y].alive() },
+		func(x, y int, alive bool) {
+			cells[x][y].stateNext = dead
+			if alive {
+				cells[x][y].stateNext = live
+			}
+		})
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/jake-shasteen/golang-gl-conway-life/pattern"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// macrocell is a pattern in Golly's macrocell format: a hashlife quadtree
+// written out node by node, so that huge, repetitive patterns stay small.
+// Each line after the header is a node, numbered from 1: either an 8x8 leaf
+// drawn with . and * and rows ending in $, or "level nw ne sw se" giving its
+// quadrants by number, with 0 for an empty one. The last node is the root.
+//
+// Only two-state patterns are supported.
+type macrocell struct {
+	h    *hashlife
+	root *node
+	rule string
+}
+
+// leafLevel is the level of the 8x8 leaves macrocell files are built from.
+const leafLevel = 3
+
+// loadMacrocell reads the macrocell file at path.
+func loadMacrocell(path string) (*macrocell, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := readMacrocell(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	return m, nil
+}
+
+func readMacrocell(r io.Reader) (*macrocell, error) {
+	h := newHashlife()
+	m := &macrocell{h: h, root: h.emptyNode(leafLevel)}
+	nodes := []*node{nil}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "["):
+		case strings.HasPrefix(line, "#R"):
+			m.rule = strings.TrimSpace(line[2:])
+		case strings.HasPrefix(line, "#"):
+			// Generation counts, descriptions and so on.
+		case line[0] >= '0' && line[0] <= '9':
+			n, err := h.macrocellNode(line, nodes)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+		default:
+			n, err := h.macrocellLeaf(line)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(nodes) > 1 {
+		m.root = nodes[len(nodes)-1]
+	}
+	return m, nil
+}
+
+// macrocellNode parses a "level nw ne sw se" line, whose quadrants are among
+// the nodes read so far.
+func (h *hashlife) macrocellNode(line string, nodes []*node) (*node, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("node %q should be a level and four quadrants", line)
+	}
+	level, err := strconv.Atoi(fields[0])
+	if err != nil || level <= leafLevel {
+		return nil, fmt.Errorf("node %q: level should be more than %d; multi-state patterns aren't supported", line, leafLevel)
+	}
+
+	var quads [4]*node
+	for i, field := range fields[1:] {
+		index, err := strconv.Atoi(field)
+		if err != nil || index < 0 || index >= len(nodes) {
+			return nil, fmt.Errorf("node %q: %q isn't an earlier node", line, field)
+		}
+		if index == 0 {
+			quads[i] = h.emptyNode(level - 1)
+			continue
+		}
+		if nodes[index].level != level-1 {
+			return nil, fmt.Errorf("node %q: node %d is level %d, want %d", line, index, nodes[index].level, level-1)
+		}
+		quads[i] = nodes[index]
+	}
+	return h.join(quads[0], quads[1], quads[2], quads[3]), nil
+}
+
+// macrocellLeaf parses an 8x8 leaf.
+func (h *hashlife) macrocellLeaf(line string) (*node, error) {
+	// grid[y][x] with y pointing down, the way the leaf is written.
+	var grid [8][8]bool
+	x, y := 0, 0
+	for _, ch := range line {
+		switch ch {
+		case '$':
+			x, y = 0, y+1
+			continue
+		case '*':
+			if x >= 8 || y >= 8 {
+				return nil, fmt.Errorf("leaf %q is bigger than 8x8", line)
+			}
+			grid[y][x] = true
+		case '.':
+		default:
+			return nil, fmt.Errorf("unexpected %q in leaf %q", ch, line)
+		}
+		x++
+	}
+
+	var quad func(level, x0, y0 int) *node
+	quad = func(level, x0, y0 int) *node {
+		if level == 0 {
+			if grid[y0][x0] {
+				return h.liveLeaf
+			}
+			return h.deadLeaf
+		}
+		half := 1 << (level - 1)
+		return h.join(quad(level-1, x0, y0), quad(level-1, x0+half, y0), quad(level-1, x0, y0+half), quad(level-1, x0+half, y0+half))
+	}
+	return quad(leafLevel, 0, 0), nil
+}
+
+// stamp replaces the board with the pattern, with the middle of its quadtree,
+// which is the origin in Golly, at the middle of the board. Cells that fall
+// off the board are left out; the rest of the quadtree is never expanded.
+func (m *macrocell) stamp(b *board) {
+	b.clear()
+	half := 1 << (m.root.level - 1)
+	m.h.write(b.cells, m.root, len(b.cells)/2-half, len(b.cells[0])/2-half)
+}
+
+// saveMacrocell writes the live cells of the board to path in macrocell
+// format, with the middle of the board at the middle of the quadtree so that
+// stamp puts them back where they were.
+func saveMacrocell(path string, cells [][]*cell) error {
+	m := newMacrocell(cells, ruleName())
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := m.write(f); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// newMacrocell builds the quadtree of the live cells of the board, with the
+// middle of the board at its middle, running under rule.
+func newMacrocell(cells [][]*cell, rule string) *macrocell {
+	h := newHashlife()
+	level := leafLevel
+	for 1<<level < len(cells) || 1<<level < len(cells[0]) {
+		level++
+	}
+	x0 := len(cells)/2 - 1<<(level-1)
+	y0 := len(cells[0])/2 - 1<<(level-1)
+	return &macrocell{h: h, root: h.build(cells, level, x0, y0), rule: rule}
+}
+
+// pattern returns the live cells of the quadtree as a pattern cropped to
+// their bounding box. Only the parts of the tree with live cells are
+// expanded.
+func (m *macrocell) pattern() *pattern.Pattern {
+	var cells [][2]int
+	var walk func(n *node, x0, y0 int)
+	walk = func(n *node, x0, y0 int) {
+		if n.population == 0 {
+			return
+		}
+		if n.level == 0 {
+			cells = append(cells, [2]int{x0, y0})
+			return
+		}
+		half := 1 << (n.level - 1)
+		walk(n.nw, x0, y0)
+		walk(n.ne, x0+half, y0)
+		walk(n.sw, x0, y0+half)
+		walk(n.se, x0+half, y0+half)
+	}
+	walk(m.root, 0, 0)
+
+	p := pattern.FromCells(cells)
+	p.Rule = m.rule
+	return p
+}
+
+func (m *macrocell) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "[M2] (golang-gl-conway-life)")
+	if m.rule != "" {
+		fmt.Fprintf(bw, "#R %v\n", m.rule)
+	}
+
+	// Each node is written after its quadrants, and numbered in that order.
+	numbers := map[*node]int{}
+	var number func(n *node) int
+	number = func(n *node) int {
+		if n.population == 0 {
+			return 0
+		}
+		if i, ok := numbers[n]; ok {
+			return i
+		}
+		if n.level == leafLevel {
+			fmt.Fprintln(bw, macrocellLeafLine(n))
+		} else {
+			nw, ne, sw, se := number(n.nw), number(n.ne), number(n.sw), number(n.se)
+			fmt.Fprintf(bw, "%d %d %d %d %d\n", n.level, nw, ne, sw, se)
+		}
+		numbers[n] = len(numbers) + 1
+		return numbers[n]
+	}
+	number(m.root)
+	return bw.Flush()
+}
+
+// macrocellLeafLine draws a level 3 node as a leaf line, leaving out dead
+// cells at the ends of rows and empty rows at the bottom.
+func macrocellLeafLine(n *node) string {
+	var grid [8][8]bool
+	var fill func(n *node, x0, y0 int)
+	fill = func(n *node, x0, y0 int) {
+		if n.population == 0 {
+			return
+		}
+		if n.level == 0 {
+			grid[y0][x0] = true
+			return
+		}
+		half := 1 << (n.level - 1)
+		fill(n.nw, x0, y0)
+		fill(n.ne, x0+half, y0)
+		fill(n.sw, x0, y0+half)
+		fill(n.se, x0+half, y0+half)
+	}
+	fill(n, 0, 0)
+
+	var sb strings.Builder
+	for _, row := range grid {
+		var line strings.Builder
+		for _, alive := range row {
+			if alive {
+				line.WriteByte('*')
+			} else {
+				line.WriteByte('.')
+			}
+		}
+		sb.WriteString(strings.TrimRight(line.String(), "."))
+		sb.WriteByte('$')
+	}
+	return strings.TrimRight(sb.String(), "$") + "$"
+}
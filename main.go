@@ -1,10 +1,17 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/jake-shasteen/golang-gl-conway-life/input"
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+	"github.com/jake-shasteen/golang-gl-conway-life/pattern"
 	"log"
+	"math"
+	"math/rand"
+	"os"
 	"runtime"
 	"strings"
 	"sync"
@@ -12,48 +19,20 @@ import (
 )
 
 const (
-	updatesPerSecond    = 10
+	title               = "Conway's Game of Life"
+	lowPowerFPS         = 30
+	lowPowerBatch       = 2 // generations stepped per wakeup in low-power mode
+	defaultTPS          = 10
+	trailCutoff         = 0.05 // heat below which a dead cell's trail isn't drawn
+	defaultWindowSize   = "640x480"
 	NUM_BYTES_IN_32_BIT = 4
-	width               = 640
-	height              = 480
-	vertexShaderSource  = `
-    #version 410
-
-    uniform float u_time;
-
-    in vec3 vp;
-    void main() {
-    		float pct = 0.9 + abs(sin(u_time / 2.0) / 10.0);
-        gl_Position = vec4(vp, pct);
-    }
-` + "\x00"
-
-	fragmentShaderSource = `
-    #version 410
-
-    uniform vec2 u_resolution;
-    uniform float u_time;
-
-    vec3 colorA = vec3(0.149,0.141,0.912);
-    vec3 colorB = vec3(1.000,0.833,0.224);
-
-    out vec4 FragColor;
-
-    void main() {
-    		vec2 st = gl_FragCoord.xy/u_resolution;
-
-        vec3 color = vec3(0.0);
-
-        float pct = (abs(sin(u_time)) + distance(st, vec2(1.0))) / 2;
+)
 
-        // Mix uses pct (a value from 0-1) to
-        // mix the two colors
-        color = mix(colorA, colorB, pct);
+// width and height are the size of the window in screen coordinates.
+var width, height int
 
-        FragColor = vec4(color,1.0);
-    }
-` + "\x00"
-)
+// highContrast fills cells in white instead of outlining them in color.
+var highContrast bool
 
 var (
 	right = []float32{
@@ -82,22 +61,329 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		doctor(os.Stdout)
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "tournament" {
+		tournament(os.Args[2:], os.Stdout)
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "convert" {
+		convert(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "experiment" {
+		experiment(os.Args[2:], os.Stdout)
+		return
+	}
+
+	crashes := &crashReporter{}
+	defer crashes.handle()
+
+	// conway play clip.lifeclip [flags] plays back a recorded demo.
+	var playing *clip
+	if len(os.Args) > 2 && os.Args[1] == "play" {
+		c, err := loadClip(os.Args[2])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		playing = c
+		os.Args = append(os.Args[:1], os.Args[3:]...)
+	}
+
+	// With no arguments at all, show off some famous patterns until the user
+	// does something.
+	splash := len(os.Args) == 1 && playing == nil
+
+	webhook := flag.String("webhook", "", "URL to POST simulation events to as JSON")
+	script := flag.String("on-generation-script", "", "run `path` every --script-every generations with the board as RLE on stdin")
+	scriptEvery := flag.Int("script-every", 100, "how many generations pass between runs of --on-generation-script")
+	triggersFile := flag.String("triggers", "", "fire actions when the board meets the conditions listed in the JSON file at `path`: take a screenshot, save the board, pause, run a shell command or POST to a webhook")
+	metricsPath := flag.String("metrics-log", "", "append notable events to `path` as JSON lines stamped with their generation: spaceships appearing, the number of objects changing and record populations")
+	changesAddr := flag.String("changes-addr", "", "stream each generation's births and deaths as JSON lines over HTTP on `addr`, e.g. :8080")
+	historySize := flag.Int("history", 256, "how many past generations to keep for the rewind key")
+	maxPopulation := flag.Int("max-population", 0, "cull cells after each generation so no more than `n` are alive; 0 for no cap")
+	cullBy := cullRandom
+	flag.Var(&cullBy, "cull", "which cells --max-population culls first: random, oldest or edge")
+	obstaclesPath := flag.String("obstacles", "", "load moving walls, spinning barriers, mazes and caves from a JSON `file`")
+	inject := flag.String("inject", "", "feed lines from `source` (- for stdin, a URL, or a file to tail) into the bottom edge")
+	post := flag.String("post", "", "run the board through post-processing `effects`, a comma-separated list of bloom, scanlines and crt; toggle with F5, F6 and F7; off with --low-power")
+	msaa := flag.Int("msaa", 0, "smooth cell outlines with `n` samples per pixel, such as 4, or 0 for none")
+	vsync := flag.String("vsync", "on", "wait for the display between frames: on or off; off with no --max-fps runs as fast as possible")
+	maxFPS := flag.Int("max-fps", 0, "cap the frame rate at `fps`, or 0 for no cap beyond the display's with --vsync=on")
+	lowPower := flag.Bool("low-power", false, "cap the frame rate, batch steps, turn off post-processing and pause when the window is unfocused")
+	colors := flag.String("palette", "gradient", "cell colors: gradient or mono")
+	themeName := flag.String("theme", "default", "color theme: default, classic, solarized, grayscale, ember, crowding, or one from themes.json in the config directory")
+	rendererName := flag.String("renderer", "instanced", "how the board is drawn: instanced, or texture to draw it as one textured quad whatever the population")
+	interpolate := flag.Bool("interpolate", false, "fade cells in and out between generations instead of switching instantly")
+	idleAfter := flag.Duration("idle-after", 30*time.Second, "how long the window may be unfocused before low-power mode pauses")
+	patternPath := flag.String("pattern", "", "start from the pattern in `file` (RLE, plaintext .cells, Life 1.05/1.06, macrocell or a PNG from conway convert), centered on an empty board")
+	ruleString := flag.String("rule", "conway", "rule to run: a rulestring such as B36/S23 or B2/S/C3, or a preset name like highlife or briansbrain; each rule brings its theme, speed and trails from profiles.json in the config directory, or built-in ones, unless flags set them")
+	ltl := flag.String("ltl", "", "run a Larger than Life rule instead, e.g. R5,B34..45,S33..57, or R7,B21..30,S20..40,NC over a disc")
+	engineName := flag.String("engine", "packed", "simulation engine: packed, naive, hashlife, gpu, or sparse for an unbounded plane the board follows")
+	flag.BoolVar(&strict, "strict", false, "for research runs, seed the board without floating point and step on one goroutine with an integer engine, packed or naive, so the same flags give the same boards everywhere")
+	warpExponent := flag.Int("warp", 8, "the warp key jumps 2^`k` generations")
+	tps := flag.Float64("tps", defaultTPS, "generations per second")
+	flag.Int64Var(&seed, "seed", 0, "random seed for the starting board, to run the same soup again; 0 picks one")
+	flag.IntVar(&columns, "cols", columns, "board width in cells")
+	flag.IntVar(&rows, "rows", rows, "board height in cells")
+	flag.Var(&boundary, "boundary", "what's past the edge of the board: wrap, dead or mirror")
+	wrap := flag.Bool("wrap", false, "shorthand for --boundary=wrap, so spaceships re-enter on the opposite edge")
+	flag.BoolVar(&highContrast, "high-contrast", false, "draw cells filled in white for projectors and low-vision users")
+	hud := flag.Bool("hud", true, "show the generation and population in the corner of the window; toggle with F1")
+	startFullscreen := flag.Bool("fullscreen", false, "start fullscreen on the primary monitor; toggle with F11")
+	trails := flag.Bool("trails", false, "leave fading trails behind cells that die; toggle with T")
+	autoFit := flag.Bool("auto-fit", false, "keep the camera fitted to the live cells as they spread; fit once with F, toggle with Ctrl+F")
+	attentionEvery := flag.Duration("attention", 0, "for unattended displays, move the camera to the most active part of the board every `interval`, such as 20s; 0 to leave the camera alone")
+	spacetimeShown := flag.Bool("spacetime", false, "show recent generations stacked into a 3D column, the newest on top, with an orbiting camera; toggle with H")
+	spacetimeLayers := flag.Int("spacetime-layers", 64, "how many generations the 3D column stacks")
+	split := flag.Bool("split", false, "show the board across two windows, the left half in one and the right half in the other")
+	recordClip := flag.String("record-clip", "", "save the starting board and settings as a .lifeclip at `path`")
+	cameraPathFile := flag.String("camera-path", "", "move the camera along the keyframes in the JSON file at `path`, a list of {\"generation\", \"x\", \"y\", \"zoom\"}, in place of a clip's own camera moves; --record-clip saves them in the clip")
+	fresh := flag.Bool("fresh", false, "start a new board instead of restoring the last session")
+	workspaceName := flag.String("workspace", "", "open the workspace saved from the palette as `name`, with its tabs and HUD settings")
+	saveOnExit := flag.String("save-on-exit", "", "save the board to `path` when the window closes, as macrocell if it ends in .mc and RLE otherwise")
+	windowSize := flag.String("window-size", defaultWindowSize, "window size as `WIDTHxHEIGHT`")
+	flag.StringVar(&shaderDir, "shader-dir", "", "read shaders from `dir`, such as ./shaders, instead of the built-in copies, and reload them when they're saved")
+	flag.StringVar(&locale, "lang", systemLocale(), "language for window titles and commands: en, es or fr")
+	flag.Parse()
+
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	// A pattern file replaces the random soup, and runs under the rule in its
+	// header unless a rule was asked for.
+	// Macrocell patterns can be far too big to lay out as rows, so they stay
+	// a quadtree until they are written onto the board.
+	var loaded *pattern.Pattern
+	var loadedTree *macrocell
+	if *patternPath != "" {
+		var err error
+		var patternRule string
+		if strings.HasSuffix(strings.ToLower(*patternPath), ".mc") {
+			loadedTree, err = loadMacrocell(*patternPath)
+			if err == nil {
+				patternRule = loadedTree.rule
+			}
+		} else {
+			loaded, err = pattern.Load(*patternPath)
+			if err == nil {
+				patternRule = loaded.Rule
+			}
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if patternRule != "" && !set["rule"] && !set["ltl"] {
+			if !chooseRule(patternRule, ruleString, ltl) {
+				log.Printf("%v has rule %q, which isn't supported; using %v", *patternPath, patternRule, *ruleString)
+			}
+		}
+	}
+
+	// A clip's settings win over flags, so it plays back as recorded.
+	if playing != nil {
+		if playing.Rule != "" && !chooseRule(playing.Rule, ruleString, ltl) {
+			log.Fatalf("The clip has rule %q, which isn't supported", playing.Rule)
+		}
+		if playing.Boundary != "" {
+			if err := boundary.Set(playing.Boundary); err != nil {
+				log.Fatalln(err)
+			}
+		}
+		if playing.Columns > 0 && playing.Rows > 0 {
+			columns, rows = playing.Columns, playing.Rows
+		}
+		if playing.Palette != "" {
+			*colors = playing.Palette
+		}
+		if speed, ok := playing.speedAt(0); ok {
+			*tps = speed
+		}
+	}
+
+	// moves drives the camera, from the clip or --camera-path, by generation.
+	var moves cameraPath
+	if playing != nil {
+		moves = playing.Camera
+	}
+	if *cameraPathFile != "" {
+		var err error
+		if moves, err = loadCameraPath(*cameraPathFile); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	// The last session comes back unless the starting board was asked for
+	// some other way. Flags still win over its settings.
+	var resumed *session
+	if !*fresh && playing == nil && !set["pattern"] && !set["seed"] && !set["cols"] && !set["rows"] && !set["workspace"] {
+		var err error
+		if resumed, err = loadSession(); err != nil {
+			log.Println("Not restoring the last session:", err)
+		}
+	}
+	if resumed != nil {
+		splash = false
+		columns, rows = resumed.Columns, resumed.Rows
+		if !set["rule"] && !set["ltl"] {
+			chooseRule(resumed.Rule, ruleString, ltl)
+		}
+		if !set["boundary"] && !set["wrap"] {
+			if err := boundary.Set(resumed.Boundary); err != nil {
+				log.Println(err)
+			}
+		}
+		if !set["tps"] {
+			*tps = resumed.TPS
+		}
+	}
+
+	// A workspace brings its own boards, so it sets the size they share.
+	var opened *savedWorkspace
+	if *workspaceName != "" {
+		var err error
+		if opened, err = loadWorkspace(*workspaceName); err != nil {
+			log.Fatalln(err)
+		}
+		columns, rows = opened.Columns, opened.Rows
+		splash = false
+	}
+
+	if _, err := fmt.Sscanf(*windowSize, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		log.Fatalf("--window-size must look like 1920x1080, got %q", *windowSize)
+	}
+	if columns <= 0 || rows <= 0 {
+		log.Fatalln("--cols and --rows must be positive")
+	}
+	if *wrap {
+		boundary = boundaryWrap
+	}
+	if *warpExponent < 0 || *warpExponent > 30 {
+		log.Fatalln("--warp must be between 0 and 30")
+	}
+
+	r, err := life.LookupRule(*ruleString)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	setRule(r)
+	if *ltl != "" {
+		if activeLtL, err = life.ParseLtL(*ltl); err != nil {
+			log.Fatalln(err)
+		}
+		log.Println("Rule: Larger than Life", activeLtL)
+	} else {
+		log.Println("Rule:", presetName(r), r)
+	}
+
+	if *colors != "gradient" && *colors != "mono" {
+		log.Fatalln("--palette must be gradient or mono")
+	}
+	if err := loadUserThemes(); err != nil {
+		log.Println("Not loading user themes:", err)
+	}
+	if activeTheme, err = lookupTheme(*themeName); err != nil {
+		log.Fatalln(err)
+	}
+	if err := loadUserProfiles(); err != nil {
+		log.Println("Not loading rule profiles:", err)
+	}
+	if *vsync != "on" && *vsync != "off" {
+		log.Fatalln("--vsync must be on or off")
+	}
+	if *msaa < 0 {
+		log.Fatalln("--msaa can't be negative")
+	}
+	if *spacetimeLayers <= 0 {
+		log.Fatalln("--spacetime-layers must be positive")
+	}
+	postEnabled, err := parsePostEffects(*post)
+	if err != nil {
+		log.Fatalln("--post:", err)
+	}
+	if shaderDir != "" {
+		if _, err := shaderSource("cell.vert"); err != nil {
+			log.Fatalln("--shader-dir should hold the files in shaders/:", err)
+		}
+	}
+	if *maxFPS < 0 {
+		log.Fatalln("--max-fps can't be negative")
+	}
+	if *rendererName != "instanced" && *rendererName != "texture" {
+		log.Fatalln("--renderer must be instanced or texture")
+	}
+	if !(*tps > 0 && *tps <= maxTPS) {
+		log.Fatalf("--tps must be above 0 and at most %.0f", maxTPS)
+	}
+	if *maxPopulation < 0 {
+		log.Fatalln("--max-population can't be negative")
+	}
+	if strict && *engineName != "packed" && *engineName != "naive" {
+		log.Fatalln("--strict needs --engine packed or naive")
+	}
+	if *attentionEvery < 0 {
+		log.Fatalln("--attention can't be negative")
+	}
+	if *attentionEvery > 0 && *autoFit {
+		log.Fatalln("--attention and --auto-fit both move the camera; choose one")
+	}
+	if *scriptEvery <= 0 {
+		log.Fatalln("--script-every must be positive")
+	}
+
+	// Each rule's profile is applied over how the game was started, leaving
+	// alone whatever flags or a clip asked for.
+	base := presentation{theme: activeTheme.name, palette: *colors, tps: *tps, trails: *trails}
+	if playing != nil {
+		set["palette"], set["tps"] = true, true
+	}
+	// filled draws live cells filled in, as high-contrast mode does, and
+	// retimed tells the simulation to pick up a new *tps.
+	filled, retimed := false, false
+	present := func() {
+		look := profiles[activeProfileKey()].over(base, set)
+		// Profiles' themes were checked when they were loaded.
+		activeTheme, _ = lookupTheme(look.theme)
+		*colors, filled, *trails = look.palette, look.filled, look.trails
+		if *tps != look.tps {
+			*tps, retimed = look.tps, true
+		}
+	}
+	present()
+
 	var mu sync.Mutex
 	if err := glfw.Init(); err != nil {
 		panic(err)
 	}
 
-	glfw.WindowHint(glfw.Resizable, glfw.False)
+	glfw.WindowHint(glfw.Resizable, glfw.True)
 	glfw.WindowHint(glfw.ContextVersionMajor, 4)
 	glfw.WindowHint(glfw.ContextVersionMinor, 1)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
 
-	window, err := glfw.CreateWindow(width, height, "Conway's Game of Life", nil, nil)
+	// --window-size is in the same units on every platform: scaled up on
+	// HiDPI monitors, as macOS already does.
+	glfw.WindowHint(glfw.ScaleToMonitor, glfw.True)
+	glfw.WindowHint(glfw.Samples, *msaa)
+
+	window, err := glfw.CreateWindow(width, height, tr(title), nil, nil)
 	if err != nil {
 		panic(err)
 	}
 	window.MakeContextCurrent()
+	if *vsync == "on" {
+		glfw.SwapInterval(1)
+	} else {
+		glfw.SwapInterval(0)
+	}
+
+	screen := &fullscreenToggle{window: window}
+	if *startFullscreen {
+		screen.toggle()
+	}
 
 	defer glfw.Terminate()
 
@@ -107,62 +393,1338 @@ func main() {
 
 	version := gl.GoStr(gl.GetString(gl.VERSION))
 	log.Println("OpenGL Version", version)
+	crashes.gl = glDiagnostics()
+
+	// The window may not have the size asked for, after scaling or going
+	// fullscreen, and on HiDPI displays its framebuffer is bigger still.
+	width, height = window.GetSize()
+	fitViewport(window)
+	framebufferWidth, framebufferHeight := window.GetFramebufferSize()
+	scaleX, scaleY := window.GetContentScale()
+	log.Printf("Window: %vx%v, framebuffer %vx%v, content scale %vx%v", width, height, framebufferWidth, framebufferHeight, scaleX, scaleY)
+
+	if *msaa > 0 {
+		gl.Enable(gl.MULTISAMPLE)
+		var samples int32
+		gl.GetIntegerv(gl.SAMPLES, &samples)
+		log.Println("MSAA samples:", samples)
+	}
+
+	shaders := newShaderCache("cell.vert", "cell.frag")
+
+	start := time.Now()
+
+	b := newBoard()
+	if b.engine, err = newEngine(*engineName); err != nil {
+		log.Fatalln(err)
+	}
+	if *engineName == "hashlife" && boundary != boundaryDead {
+		log.Printf("Hashlife only runs the dead boundary; under %v the naive engine steps instead", boundary)
+	}
+	cells := b.cells
+	crashes.cells = cells
+	log.Println("Seed:", seed)
+	if loaded != nil {
+		b.clear()
+		b.stamp(loaded.Rows)
+	}
+	if loadedTree != nil {
+		loadedTree.stamp(b)
+	}
+	if resumed != nil {
+		b.Load(resumed.Cells)
+		view = resumed.Camera
+		log.Println("Restored the last session; run with --fresh to start over")
+	}
+	if playing != nil {
+		playing.stamp(b)
+	}
+	if *recordClip != "" {
+		if err := saveClip(*recordClip, cells, *colors, *tps, moves); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	cellsRenderer := newCellRenderer()
+	gradients := newGradientTexture()
+
+	// The texture renderer draws the board itself; diffs, interpolation and
+	// everything drawn over the board stay instanced.
+	var boardRenderer *textureRenderer
+	boardShaders := newShaderCache("board.vert", "board.frag")
+	if *rendererName == "texture" {
+		boardRenderer = newTextureRenderer()
+	}
+	hudText := newTextRenderer()
+	textShaders := newShaderCache("text.vert", "text.frag")
+	grid := newGridOverlay()
+	gridShaders := newShaderCache("board.vert", "grid.frag")
+	showGrid := false
+	effects := newPostChain(postEnabled)
+	if *lowPower {
+		effects.off = true
+		if len(postEnabled) > 0 {
+			log.Println("Post-processing is off in low-power mode")
+		}
+	}
 
-	vertexShader, err := compileShader(vertexShaderSource, gl.VERTEX_SHADER)
+	// The 3D column replaces the board in the main window while it's shown,
+	// and only captures generations then.
+	spacetime := newSpacetimeView(*spacetimeLayers)
+	spacetimeShaders := newShaderCache("spacetime.vert", "spacetime.frag")
+	showSpacetime := *spacetimeShown
+	if showSpacetime {
+		spacetime.capture(cells)
+	}
+	markers := []map[boundaryMode]boundaryMarker{makeBoundaryMarkers()}
+	var second *glfw.Window
+	if *split {
+		var secondMarkers map[boundaryMode]boundaryMarker
+		second, secondMarkers = openSplitWindow(window, cellsRenderer, boardRenderer, grid, effects)
+		markers = append(markers, secondMarkers)
+		window.MakeContextCurrent()
+	}
+	meta := newChannels(cells, channelAge|channelHeat)
+
+	var triggers []*trigger
+	if *triggersFile != "" {
+		var err error
+		if triggers, err = loadTriggers(*triggersFile); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	notify := newNotifier(*webhook)
+	notify.emit("started", 0, population(cells))
+	var watch watcher
+	switch {
+	case resumed != nil:
+		watch.generation = resumed.Generation
+	case loaded != nil:
+		// A board saved as PNG says which generation it was.
+		watch.generation = loaded.Generation
+	}
+	crashes.generation = &watch.generation
+	metrics, err := newMetricsLog(*metricsPath)
 	if err != nil {
-		panic(err)
+		log.Fatalln(err)
 	}
-	fragmentShader, err := compileShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
+	watch.population = population(cells)
+	metrics.observe(cells, &watch, 0)
+	hook := newGenerationHook(*script, *scriptEvery)
+	changes := newChangeFeed(*changesAddr)
+
+	injector, err := newInjector(*inject)
 	if err != nil {
 		panic(err)
 	}
+	obstacles, err := loadObstacles(*obstaclesPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	obstacles.apply(cells, watch.generation)
 
-	prog := gl.CreateProgram()
+	// reference holds the generation the board is being diffed against, or nil
+	// when diff mode is off.
+	var reference [][]bool
 
-	gl.AttachShader(prog, vertexShader)
-	gl.AttachShader(prog, fragmentShader)
-	gl.LinkProgram(prog)
+	// Edits made with the mouse wait in edits until the next generation.
+	// painting is the state a held mouse button drags onto cells.
+	var edits editQueue
+	var painting *bool
 
-	start := time.Now()
+	// dragFrom is where the cursor was when the camera was last panned by
+	// dragging, in window coordinates.
+	var dragFrom [2]float64
 
-	cells := makeCells()
+	paint := func(xpos, ypos float64) {
+		x, y, ok := cellAt(cells, xpos, ypos, time.Since(start).Seconds())
+		if !ok {
+			return
+		}
+
+		mu.Lock()
+		if painting == nil {
+			alive := !cells[x][y].alive()
+			painting = &alive
+		}
+		edits.add(edit{x: x, y: y, alive: *painting})
+		mu.Unlock()
+	}
+
+	// previous and lastStep let the renderer fade between the last two
+	// generations drawn when interpolating.
+	previous := snapshot(cells)
+	lastStep := time.Now()
+
+	// initial is the board as it started, for the reset key. While paused,
+	// generations only run one at a time with the step key.
+	initial := snapshot(cells)
+	paused := false
+
+	// past holds recent generations for the rewind key, and scrub moves
+	// through them without forgetting them.
+	past := newHistory(*historySize)
+	var scrub scrubber
+
+	// screenshots are where to save the next frame drawn, for triggers.
+	var screenshots []string
+
+	// fire carries out the actions of the triggers that fired which need
+	// the app, the rest having been done by checkTriggers. The caller must
+	// hold the lock.
+	fire := func(fired []*trigger) {
+		for _, t := range fired {
+			name := time.Now().Format("life-20060102-150405") + fmt.Sprintf("-gen%d", watch.generation)
+			if t.has("save") {
+				if err := saveBoard(name+".rle", cells); err != nil {
+					log.Println("saving board:", err)
+				} else {
+					log.Println("Saved board to", name+".rle")
+				}
+			}
+			if t.has("screenshot") {
+				screenshots = append(screenshots, name+".png")
+			}
+			if t.has("pause") {
+				paused = true
+			}
+		}
+	}
+
+	// track follows the object picked with Track. Its periods only count
+	// generations stepped through one at a time, so anything that jumps to
+	// another generation stops it.
+	var track tracker
+
+	// advance runs the next n generations, along with everything that
+	// happens between them. The caller must hold the lock.
+	perf := newPerfCounter()
+	showPerf := false
+	advance := func(n int) {
+		stepStarted := time.Now()
+		defer func() { perf.step(n, time.Since(stepStarted)) }()
+
+		previous = snapshot(cells)
+		lastStep = time.Now()
+		edits.apply(cells)
+		injector.apply(cells)
+		for i := 0; i < n; i++ {
+			past.push(b, watch.generation)
+			b.Step()
+			obstacles.apply(cells, watch.generation+1)
+			if *maxPopulation > 0 {
+				cull(cells, meta, *maxPopulation, cullBy)
+			}
+			meta.update(cells)
+			if showSpacetime {
+				spacetime.capture(cells)
+			}
+			watch.observe(cells, notify, 1)
+			metrics.observe(cells, &watch, 1)
+			track.observe(cells)
+			changes.observe(cells, watch.generation)
+			fire(checkTriggers(triggers, &watch, cells))
+			if paused {
+				break
+			}
+		}
+		hook.observe(cells, watch.generation)
+	}
+
+	// carried is a pasted pattern following the cursor until it is placed.
+	// placing is set while the click that placed it is held, so the drag
+	// doesn't paint.
+	var carried []string
+	placing := false
+	place := func(x, y int) {
+		mu.Lock()
+		for _, ed := range stampEdits(carried, x, y) {
+			edits.add(ed)
+		}
+		carried = nil
+		mu.Unlock()
+	}
+
+	// keyCursor is the cell the keyboard cursor is on, shown once it has
+	// been moved.
+	keyCursor := point{columns / 2, rows / 2}
+	keyCursorShown := false
+	moveKeyCursor := func(dx, dy int) {
+		mu.Lock()
+		keyCursor.x = (keyCursor.x + dx + columns) % columns
+		keyCursor.y = (keyCursor.y + dy + rows) % rows
+		keyCursorShown = true
+		mu.Unlock()
+	}
+
+	// pan moves the camera by a tenth of the window.
+	pan := func(dx, dy float32) {
+		mu.Lock()
+		view.X += dx / 10 / view.Zoom
+		view.Y += dy / 10 / view.Zoom
+		mu.Unlock()
+	}
+	zoom := func(by float32) {
+		mu.Lock()
+		if showSpacetime {
+			spacetime.zoom *= by
+		} else {
+			view.Zoom *= by
+		}
+		mu.Unlock()
+	}
+
+	// The showcase picks patterns from the board's seed, so --seed plays
+	// them back in the same order.
+	showcaseRNG := rand.New(rand.NewSource(seed))
+	showcaseIndex := -1
+	var nextShowcase time.Time
+
+	var mapper *input.Mapper
+	pal := &palette{}
+	pick := &picker{}
+	marks := &bookmarks{}
+
+	// tabs holds the boards set aside while another is shown. The caller of
+	// stash and show must hold the lock.
+	tabs := newWorkspace()
+	stash := func() *tab {
+		return &tab{
+			state:    b.Save(nil),
+			initial:  initial,
+			watch:    watch,
+			paused:   paused,
+			rule:     activeRule,
+			ltl:      activeLtL,
+			boundary: boundary,
+			view:     view,
+			past:     past,
+			marks:    marks,
+		}
+	}
+	show := func(t *tab) {
+		b.Load(t.state)
+		initial, watch, paused = t.initial, t.watch, t.paused
+		setRule(t.rule)
+		activeLtL = t.ltl
+		boundary, view = t.boundary, t.view
+		past, marks = t.past, t.marks
+		present()
+
+		// Nothing about the last board carries over.
+		scrub = scrubber{}
+		edits = editQueue{}
+		carried, reference = nil, nil
+		track.stop()
+		hook.rewind(watch.generation)
+		meta = newChannels(cells, channelAge|channelHeat)
+		previous = snapshot(cells)
+		spacetime.clear()
+		if showSpacetime {
+			spacetime.capture(cells)
+		}
+	}
+	switchTab := func(to int) {
+		tabs.tabs[tabs.current] = stash()
+		tabs.current = to
+		show(tabs.tabs[to])
+	}
+
+	// openWorkspace replaces every tab with the workspace's, which must have
+	// the board's size. The caller must hold the lock.
+	openWorkspace := func(w *savedWorkspace) error {
+		var opening []*tab
+		for _, s := range w.Tabs {
+			t, err := s.tab(columns, rows, *historySize)
+			if err != nil {
+				return err
+			}
+			opening = append(opening, t)
+		}
+		tabs.tabs, tabs.current = opening, w.Current
+		*hud, showPerf, showGrid = w.HUD, w.Perf, w.Grid
+		show(tabs.tabs[tabs.current])
+		return nil
+	}
+	if opened != nil {
+		if err := openWorkspace(opened); err != nil {
+			log.Fatalf("opening workspace %q: %v", *workspaceName, err)
+		}
+	}
+	prompt := &namePrompt{}
+	askName := func(format string, entered func(name string)) {
+		prompt.format, prompt.name = format, ""
+		window.SetTitle(prompt.title())
+		mapper.CaptureText(func(t input.Text) {
+			open, ok := prompt.handle(t)
+			if open {
+				window.SetTitle(prompt.title())
+				return
+			}
+			mapper.CaptureText(nil)
+			window.SetTitle(tr(title))
+			if ok {
+				entered(prompt.name)
+			}
+		})
+	}
+
+	mapper = input.New(input.DefaultKeymap, func(e input.Event) {
+		if splash {
+			splash = false
+			window.SetTitle(tr(title))
+		}
+
+		// Anything that moves the board on from a generation scrubbed to
+		// keeps that generation, and forgets the ones after it.
+		switch e.Action {
+		case input.Pause, input.StepOnce, input.Rewind, input.Reset, input.Warp, input.NewTab, input.NextTab, input.PreviousTab, input.CloseTab:
+			mu.Lock()
+			settled := scrub.settle(past)
+			mu.Unlock()
+			if settled {
+				window.SetTitle(tr(title))
+			}
+		}
+
+		switch e.Action {
+		case input.Pause:
+			mu.Lock()
+			paused = !paused
+			mu.Unlock()
+			log.Println("Paused:", paused)
+		case input.StepOnce:
+			mu.Lock()
+			if paused {
+				advance(1)
+			}
+			mu.Unlock()
+		case input.ScrubBack, input.ScrubForward:
+			by := 1
+			if e.Action == input.ScrubForward {
+				by = -1
+			}
+			mu.Lock()
+			paused = true
+			generation, ok := scrub.move(past, b, watch.generation, by)
+			if ok {
+				watch.generation = generation
+				track.stop()
+				hook.rewind(generation)
+				meta.update(cells)
+				previous = snapshot(cells)
+				if by > 0 {
+					spacetime.pop()
+				} else if showSpacetime {
+					spacetime.capture(cells)
+				}
+			}
+			scrubbing := scrub.back > 0
+			status := scrub.title(past, watch.generation)
+			mu.Unlock()
+			if scrubbing {
+				window.SetTitle(status)
+			} else if ok {
+				window.SetTitle(tr(title))
+			}
+		case input.Rewind:
+			mu.Lock()
+			paused = true
+			if generation, ok := past.pop(b); ok {
+				watch.generation = generation
+				track.stop()
+				hook.rewind(generation)
+				meta.update(cells)
+				previous = snapshot(cells)
+				spacetime.pop()
+			}
+			mu.Unlock()
+		case input.Reset:
+			mu.Lock()
+			b.restore(initial)
+			past.clear()
+			watch = watcher{}
+			track.stop()
+			hook.rewind(0)
+			obstacles.apply(cells, 0)
+			meta.update(cells)
+			previous = snapshot(cells)
+			spacetime.clear()
+			if showSpacetime {
+				spacetime.capture(cells)
+			}
+			mu.Unlock()
+			log.Println("Reset to the starting board")
+		case input.ToggleDiff:
+			mu.Lock()
+			if reference == nil {
+				reference = snapshot(cells)
+			} else {
+				reference = nil
+			}
+			mu.Unlock()
+		case input.ToggleTrails:
+			mu.Lock()
+			*trails = !*trails
+			mu.Unlock()
+		case input.ToggleFullscreen:
+			screen.toggle()
+		case input.ToggleHUD:
+			*hud = !*hud
+		case input.TogglePerf:
+			mu.Lock()
+			showPerf = !showPerf
+			mu.Unlock()
+		case input.ToggleGrid:
+			mu.Lock()
+			showGrid = !showGrid
+			mu.Unlock()
+		case input.ToggleBloom:
+			mu.Lock()
+			effects.toggle("bloom")
+			mu.Unlock()
+		case input.ToggleScanlines:
+			mu.Lock()
+			effects.toggle("scanlines")
+			mu.Unlock()
+		case input.ToggleCRT:
+			mu.Lock()
+			effects.toggle("crt")
+			mu.Unlock()
+		case input.ToggleSpacetime:
+			// The column starts again from the board as it is, since
+			// generations weren't captured while it was hidden.
+			mu.Lock()
+			showSpacetime = !showSpacetime
+			if showSpacetime {
+				spacetime.clear()
+				spacetime.capture(cells)
+			}
+			mu.Unlock()
+		case input.CycleRule:
+			mu.Lock()
+			setRule(nextPreset(activeRule))
+			present()
+			mu.Unlock()
+			log.Println("Rule:", presetName(activeRule), activeRule)
+		case input.CycleBoundary:
+			mu.Lock()
+			boundary = boundary.next()
+			mu.Unlock()
+			log.Println("Boundary:", boundary)
+		case input.Warp:
+			mu.Lock()
+			past.push(b, watch.generation)
+			b.Warp(*warpExponent)
+			track.stop()
+			obstacles.apply(cells, watch.generation+1<<*warpExponent)
+			if *maxPopulation > 0 {
+				cull(cells, meta, *maxPopulation, cullBy)
+			}
+			meta.update(cells)
+			if showSpacetime {
+				spacetime.capture(cells)
+			}
+			watch.observe(cells, notify, 1<<*warpExponent)
+			metrics.observe(cells, &watch, 1<<*warpExponent)
+			fire(checkTriggers(triggers, &watch, cells))
+			changes.observe(cells, watch.generation)
+			hook.observe(cells, watch.generation)
+			mu.Unlock()
+			log.Printf("Warped %v generations", 1<<*warpExponent)
+		case input.SaveBoard:
+			path := time.Now().Format("life-20060102-150405.rle")
+			mu.Lock()
+			err := saveBoard(path, cells)
+			mu.Unlock()
+			if err != nil {
+				log.Println("saving board:", err)
+			} else {
+				log.Println("Saved board to", path)
+			}
+		case input.Copy:
+			var rle strings.Builder
+			mu.Lock()
+			err := encodeRLE(&rle, snapshot(cells), ruleName())
+			mu.Unlock()
+			if err != nil {
+				log.Println("copying board:", err)
+			} else {
+				window.SetClipboardString(rle.String())
+				log.Println("Copied board to the clipboard as RLE")
+			}
+		case input.Bookmark:
+			mu.Lock()
+			mark := marks.add(b, watch.generation)
+			mu.Unlock()
+			mark.path = time.Now().Format("life-20060102-150405") + fmt.Sprintf("-gen%d.png", mark.generation)
+			if err := savePNG(mark.path, mark.thumbnail); err != nil {
+				log.Println("saving bookmark thumbnail:", err)
+				mark.path = ""
+			}
+			log.Printf("Bookmarked generation %v (%v of them), with a thumbnail in %v", mark.generation, len(marks.marks), mark.path)
+		case input.OpenBookmarks:
+			if len(marks.marks) == 0 {
+				log.Println("No bookmarks yet; press M to mark a generation")
+				break
+			}
+			marks.open = true
+			window.SetTitle(marks.title())
+			mapper.CaptureText(func(t input.Text) {
+				open, jump := marks.handle(t)
+				if jump {
+					// The board it jumps from can be gone back to with
+					// Rewind.
+					mu.Lock()
+					scrub.settle(past)
+					past.push(b, watch.generation)
+					paused = true
+					watch.generation = marks.jump(b)
+					track.stop()
+					hook.rewind(watch.generation)
+					meta.update(cells)
+					previous = snapshot(cells)
+					spacetime.clear()
+					if showSpacetime {
+						spacetime.capture(cells)
+					}
+					generation := watch.generation
+					mu.Unlock()
+					log.Println("Jumped to generation", generation)
+				}
+				if open {
+					window.SetTitle(marks.title())
+				} else {
+					mapper.CaptureText(nil)
+					window.SetTitle(tr(title))
+				}
+			})
+		case input.OpenPalette:
+			pal.open, pal.query, pal.selected = true, "", 0
+			window.SetTitle(pal.title())
+			mapper.CaptureText(func(t input.Text) {
+				open, run := pal.handle(t)
+				if open {
+					window.SetTitle(pal.title())
+					return
+				}
+				mapper.CaptureText(nil)
+				window.SetTitle(tr(title))
+				if run != nil {
+					run()
+				}
+			})
+		case input.Stamp:
+			// The pattern goes under the keyboard cursor once it has been
+			// moved, and under the mouse otherwise.
+			mu.Lock()
+			at := keyCursor
+			if x, y, ok := cellAt(cells, e.X, e.Y, time.Since(start).Seconds()); ok && !keyCursorShown {
+				at = point{x, y}
+			}
+			mu.Unlock()
+
+			pick.open = true
+			window.SetTitle(pick.title())
+			mapper.CaptureText(func(t input.Text) {
+				open, stamp := pick.handle(t)
+				if stamp {
+					chosen := library[pick.selected]
+					stamped := chosen.load()
+					mu.Lock()
+					for _, ed := range stampEdits(stamped.Rows, at.x, at.y) {
+						edits.add(ed)
+					}
+					mu.Unlock()
+					log.Println("Stamped", chosen.name)
+				}
+				if open {
+					window.SetTitle(pick.title())
+				} else {
+					mapper.CaptureText(nil)
+					window.SetTitle(tr(title))
+				}
+			})
+		case input.NewTab:
+			// A new tab starts from a soup of its own, made from the seed so
+			// --seed opens the same ones again, under the same rule.
+			mu.Lock()
+			tabs.tabs[tabs.current] = stash()
+			state := randomState(seed + int64(len(tabs.tabs)))
+			b.Load(state)
+			fresh := stash()
+			fresh.initial, fresh.watch, fresh.paused = snapshot(cells), watcher{}, false
+			fresh.view = camera{Zoom: 1}
+			fresh.past, fresh.marks = newHistory(*historySize), &bookmarks{}
+			tabs.tabs = append(tabs.tabs, fresh)
+			tabs.current = len(tabs.tabs) - 1
+			show(fresh)
+			n := len(tabs.tabs)
+			mu.Unlock()
+			log.Printf("Opened tab %v", n)
+		case input.NextTab, input.PreviousTab:
+			by := 1
+			if e.Action == input.PreviousTab {
+				by = -1
+			}
+			mu.Lock()
+			to := tabs.move(by)
+			if to != tabs.current {
+				switchTab(to)
+			}
+			mu.Unlock()
+			log.Printf("Tab %v of %v", to+1, len(tabs.tabs))
+		case input.CloseTab:
+			mu.Lock()
+			next, ok := tabs.close()
+			if ok {
+				show(next)
+			}
+			mu.Unlock()
+			if !ok {
+				log.Println("The last tab can't be closed")
+			}
+		case input.Track:
+			// Like Stamp, this picks from under the keyboard cursor once it
+			// has been moved.
+			mu.Lock()
+			at := keyCursor
+			if x, y, ok := cellAt(cells, e.X, e.Y, time.Since(start).Seconds()); ok && !keyCursorShown {
+				at = point{x, y}
+			}
+			tracking := track.start(cells, at.x, at.y)
+			mu.Unlock()
+			if tracking {
+				log.Println("Tracking the object at", at.x, at.y)
+			} else {
+				log.Println("Stopped tracking")
+			}
+		case input.CursorUp:
+			moveKeyCursor(0, 1)
+		case input.CursorDown:
+			moveKeyCursor(0, -1)
+		case input.CursorLeft:
+			moveKeyCursor(-1, 0)
+		case input.CursorRight:
+			moveKeyCursor(1, 0)
+		case input.Paste:
+			pasted, err := pattern.ReadRLE(strings.NewReader(window.GetClipboardString()))
+			if err != nil {
+				log.Println("The clipboard doesn't hold an RLE pattern:", err)
+				break
+			}
+			mu.Lock()
+			carried = pasted.Rows
+			mu.Unlock()
+			log.Println("Click or press Enter to place the pasted pattern, or Escape to drop it")
+		case input.Cancel:
+			mu.Lock()
+			carried = nil
+			mu.Unlock()
+		case input.ToggleCell:
+			if carried != nil {
+				place(keyCursor.x, keyCursor.y)
+				break
+			}
+			mu.Lock()
+			keyCursorShown = true
+			edits.add(edit{x: keyCursor.x, y: keyCursor.y, alive: !cells[keyCursor.x][keyCursor.y].alive()})
+			mu.Unlock()
+		case input.FitCamera:
+			mu.Lock()
+			view = view.fit(snapshot(cells))
+			mu.Unlock()
+		case input.ToggleAutoFit:
+			mu.Lock()
+			*autoFit = !*autoFit
+			mu.Unlock()
+			log.Println("Auto-fit:", *autoFit)
+		case input.PanUp:
+			pan(0, 1)
+		case input.PanDown:
+			pan(0, -1)
+		case input.PanLeft:
+			pan(-1, 0)
+		case input.PanRight:
+			pan(1, 0)
+		case input.ZoomIn:
+			zoom(1.25)
+		case input.ZoomOut:
+			zoom(0.8)
+		case input.PaintStart:
+			// Cells can't be picked out of the 3D column to edit.
+			if showSpacetime {
+				break
+			}
+			if carried != nil {
+				if x, y, ok := cellAt(cells, e.X, e.Y, time.Since(start).Seconds()); ok {
+					place(x, y)
+					placing = true
+				}
+				break
+			}
+			paint(e.X, e.Y)
+		case input.PaintMove:
+			if !placing && !showSpacetime {
+				paint(e.X, e.Y)
+			}
+		case input.PaintEnd:
+			painting = nil
+			placing = false
+		case input.DragPanStart:
+			dragFrom = [2]float64{e.X, e.Y}
+		case input.DragPanMove:
+			if showSpacetime {
+				mu.Lock()
+				spacetime.orbit(-float32(e.X-dragFrom[0])/100, float32(e.Y-dragFrom[1])/100)
+				mu.Unlock()
+				dragFrom = [2]float64{e.X, e.Y}
+				break
+			}
+			// Keep the point of the board that was grabbed under the cursor.
+			seconds := time.Since(start).Seconds()
+			mu.Lock()
+			fromX, fromY := windowToBoard(dragFrom[0], dragFrom[1], seconds)
+			toX, toY := windowToBoard(e.X, e.Y, seconds)
+			view.X -= float32(toX - fromX)
+			view.Y -= float32(toY - fromY)
+			mu.Unlock()
+			dragFrom = [2]float64{e.X, e.Y}
+		case input.WheelZoom:
+			if showSpacetime {
+				zoom(float32(math.Pow(1.1, e.Scroll)))
+				break
+			}
+			// Zoom, then move the camera so the point of the board that was
+			// under the cursor still is.
+			seconds := time.Since(start).Seconds()
+			mu.Lock()
+			beforeX, beforeY := windowToBoard(e.X, e.Y, seconds)
+			view.Zoom *= float32(math.Pow(1.1, e.Scroll))
+			afterX, afterY := windowToBoard(e.X, e.Y, seconds)
+			view.X += float32(beforeX - afterX)
+			view.Y += float32(beforeY - afterY)
+			mu.Unlock()
+		}
+	})
+	mapper.Attach(window)
+
+	// The window can be resized; the viewport follows its framebuffer, and
+	// width and height its size, which is what cursor positions are in.
+	window.SetFramebufferSizeCallback(func(w *glfw.Window, framebufferWidth, framebufferHeight int) {
+		gl.Viewport(0, 0, int32(framebufferWidth), int32(framebufferHeight))
+	})
+	window.SetSizeCallback(func(w *glfw.Window, windowWidth, windowHeight int) {
+		width, height = windowWidth, windowHeight
+	})
+
+	// Pattern files dropped on the window are stamped centered where they
+	// land.
+	window.SetDropCallback(func(w *glfw.Window, names []string) {
+		xpos, ypos := w.GetCursorPos()
+		x, y, ok := cellAt(cells, xpos, ypos, time.Since(start).Seconds())
+		if !ok {
+			log.Println("Dropped off the board")
+			return
+		}
+
+		for _, name := range names {
+			dropped, err := pattern.Load(name)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			mu.Lock()
+			for _, ed := range stampEdits(dropped.Rows, x, y) {
+				edits.add(ed)
+			}
+			mu.Unlock()
+			log.Println("Stamped", name)
+		}
+	})
+
+	pal.commands = []command{
+		{tr("Toggle diff view"), func() { mapper.Dispatch(input.ToggleDiff) }},
+		{tr("Toggle death trails"), func() { mapper.Dispatch(input.ToggleTrails) }},
+		{tr("Toggle grid lines"), func() { mapper.Dispatch(input.ToggleGrid) }},
+		{tr("Toggle fullscreen"), func() { mapper.Dispatch(input.ToggleFullscreen) }},
+		{tr("Toggle HUD"), func() { mapper.Dispatch(input.ToggleHUD) }},
+		{tr("Toggle performance counter"), func() { mapper.Dispatch(input.TogglePerf) }},
+		{tr("Toggle bloom"), func() { mapper.Dispatch(input.ToggleBloom) }},
+		{tr("Toggle scanlines"), func() { mapper.Dispatch(input.ToggleScanlines) }},
+		{tr("Toggle CRT curvature"), func() { mapper.Dispatch(input.ToggleCRT) }},
+		{tr("Toggle 3D history"), func() { mapper.Dispatch(input.ToggleSpacetime) }},
+		{tr("Cycle boundary mode"), func() { mapper.Dispatch(input.CycleBoundary) }},
+		{tr("Next rule preset"), func() { mapper.Dispatch(input.CycleRule) }},
+		{tr("Warp ahead"), func() { mapper.Dispatch(input.Warp) }},
+		{tr("Pause or resume"), func() { mapper.Dispatch(input.Pause) }},
+		{tr("Step one generation"), func() { mapper.Dispatch(input.StepOnce) }},
+		{tr("Step back one generation"), func() { mapper.Dispatch(input.Rewind) }},
+		{tr("Reset to the starting board"), func() { mapper.Dispatch(input.Reset) }},
+		{tr("Save board as RLE"), func() { mapper.Dispatch(input.SaveBoard) }},
+		{tr("Copy board as RLE"), func() { mapper.Dispatch(input.Copy) }},
+		{tr("Stamp a pattern from the library"), func() { mapper.Dispatch(input.Stamp) }},
+		{tr("Paste a pattern from the clipboard"), func() { mapper.Dispatch(input.Paste) }},
+		{tr("Fit the camera to the live cells"), func() { mapper.Dispatch(input.FitCamera) }},
+		{tr("Toggle auto-fit"), func() { mapper.Dispatch(input.ToggleAutoFit) }},
+		{tr("Bookmark this generation"), func() { mapper.Dispatch(input.Bookmark) }},
+		{tr("Jump to a bookmark"), func() { mapper.Dispatch(input.OpenBookmarks) }},
+		{tr("Track the object under the cursor"), func() { mapper.Dispatch(input.Track) }},
+		{tr("New tab"), func() { mapper.Dispatch(input.NewTab) }},
+		{tr("Next tab"), func() { mapper.Dispatch(input.NextTab) }},
+		{tr("Previous tab"), func() { mapper.Dispatch(input.PreviousTab) }},
+		{tr("Close tab"), func() { mapper.Dispatch(input.CloseTab) }},
+		{tr("Save workspace"), func() {
+			askName("Save workspace as: %v  (saved: %v; Enter to save)", func(name string) {
+				mu.Lock()
+				tabs.tabs[tabs.current] = stash()
+				w := &savedWorkspace{Columns: columns, Rows: rows, Current: tabs.current, HUD: *hud, Perf: showPerf, Grid: showGrid}
+				for _, t := range tabs.tabs {
+					w.Tabs = append(w.Tabs, saveTab(t))
+				}
+				mu.Unlock()
+				if err := saveWorkspace(name, w); err != nil {
+					log.Println("saving workspace:", err)
+				} else {
+					log.Printf("Saved %v tabs as workspace %q", len(w.Tabs), name)
+				}
+			})
+		}},
+		{tr("Open workspace"), func() {
+			askName("Open workspace: %v  (saved: %v; Enter to open)", func(name string) {
+				w, err := loadWorkspace(name)
+				if err == nil && (w.Columns != columns || w.Rows != rows) {
+					err = fmt.Errorf("it has %vx%v boards, not %vx%v; open it with --workspace %v", w.Columns, w.Rows, columns, rows, name)
+				}
+				if err == nil {
+					mu.Lock()
+					scrub.settle(past)
+					err = openWorkspace(w)
+					mu.Unlock()
+				}
+				if err != nil {
+					log.Printf("opening workspace %q: %v", name, err)
+				} else {
+					log.Printf("Opened workspace %q", name)
+				}
+			})
+		}},
+	}
+	for _, p := range life.Presets {
+		r := p.Rule
+		pal.commands = append(pal.commands, command{fmt.Sprintf(tr("Rule: %v"), p.Name), func() {
+			mu.Lock()
+			setRule(r)
+			present()
+			mu.Unlock()
+			log.Println("Rule:", presetName(r), r)
+		}})
+	}
+	for _, t := range themes {
+		t := t
+		pal.commands = append(pal.commands, command{fmt.Sprintf(tr("Theme: %v"), t.name), func() {
+			activeTheme = t
+			log.Println("Theme:", t.name)
+		}})
+	}
+	for _, mode := range []boundaryMode{boundaryWrap, boundaryDead, boundaryMirror} {
+		mode := mode
+		pal.commands = append(pal.commands, command{fmt.Sprintf(tr("Boundary: %v"), mode), func() {
+			mu.Lock()
+			boundary = mode
+			mu.Unlock()
+			log.Println("Boundary:", boundary)
+		}})
+	}
+
+	// unfocusedSince is when the window lost focus, or zero while it has it.
+	var unfocusedSince time.Time
+	window.SetFocusCallback(func(w *glfw.Window, focused bool) {
+		mu.Lock()
+		if focused {
+			unfocusedSince = time.Time{}
+		} else {
+			unfocusedSince = time.Now()
+		}
+		mu.Unlock()
+	})
+
+	ticks := newScheduler(*tps)
+
+	// Low-power mode steps the same number of generations per second, but
+	// several at a time so the goroutine wakes up less often.
+	batch := 1
+	if *lowPower {
+		batch = lowPowerBatch
+	}
+
+	stepInterval := time.Duration(batch) * ticks.interval
 
 	go func() {
+		defer crashes.handle()
 		for !window.ShouldClose() {
-			t := time.Now()
+			now := time.Now()
 
 			mu.Lock()
-			for x := range cells {
-				for _, c := range cells[x] {
-					c.checkState(cells)
+			idle := *lowPower && !unfocusedSince.IsZero() && time.Since(unfocusedSince) > *idleAfter
+			if retimed {
+				ticks.setRate(*tps)
+				stepInterval = time.Duration(batch) * ticks.interval
+				retimed = false
+			}
+			if n := ticks.due(now); n > 0 && !idle && !paused {
+				advance(n)
+
+				if playing != nil {
+					if speed, ok := playing.speedAt(watch.generation); ok {
+						ticks.setRate(speed)
+						stepInterval = time.Duration(batch) * ticks.interval
+					}
 				}
 			}
 			mu.Unlock()
 
-			time.Sleep(time.Second/time.Duration(updatesPerSecond) - time.Since(t))
+			time.Sleep(ticks.until(time.Now()) + time.Duration(batch-1)*ticks.interval)
 		}
 	}()
 
-	for !window.ShouldClose() {
-		gl.Uniform1f(gl.GetUniformLocation(prog, gl.Str("u_time\x00")), float32(time.Since(start).Seconds()))
-		gl.Uniform2f(gl.GetUniformLocation(prog, gl.Str("u_resolution\x00")), width, height)
+	// fps is 0 for no pacing at all, with vsync off and no cap.
+	fps := 0
+	if *vsync == "on" {
+		fps = refreshRate(window)
+	}
+	if *maxFPS > 0 && (fps == 0 || fps > *maxFPS) {
+		fps = *maxFPS
+	}
+	if *lowPower && (fps == 0 || fps > lowPowerFPS) {
+		fps = lowPowerFPS
+	}
+	pacer := newFramePacer(fps)
+	shaderEdits := newShaderWatcher()
+
+	// fitTarget is where --auto-fit is taking the camera, worked out at
+	// fitGeneration.
+	var fitTarget camera
+	var fitGeneration int
+	var fitted bool
+	lastFit := time.Now()
+	attend := newAttention(*attentionEvery)
+
+	for !window.ShouldClose() && (second == nil || !second.ShouldClose()) {
+		frameStarted := time.Now()
+
+		if shaderEdits.changed() {
+			for _, s := range []*shaderCache{shaders, boardShaders, gridShaders, textShaders, effects.shaders, spacetimeShaders} {
+				s.reload()
+			}
+			log.Println("Reloaded shaders from", shaderDir)
+		}
+
+		var features []string
+		if *interpolate {
+			features = append(features, "TRAILS")
+		}
+		if *colors == "mono" {
+			features = append(features, "PALETTE_MONO")
+		}
+		if highContrast {
+			features = append(features, "HIGH_CONTRAST")
+		}
+		if filled {
+			features = append(features, "FILLED")
+		}
+		prog := shaders.program(features...)
+
+		// High-contrast mode is always on black.
+		background := activeTheme.background
+		if highContrast {
+			background = rgb{}
+		}
 
+		uniforms := func(p uint32, w *glfw.Window, offset float32) {
+			setUniforms(p, w, offset, float32(time.Since(start).Seconds()), background, gradients)
+		}
+
+		effects.begin(window)
+		gl.ClearColor(background[0], background[1], background[2], 1)
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		gl.UseProgram(prog)
+		overlayLocation := gl.GetUniformLocation(prog, gl.Str("u_overlay\x00"))
 
 		mu.Lock()
-		for x := range cells {
-			for _, c := range cells[x] {
-				c.draw()
+		if splash && !time.Now().Before(nextShowcase) {
+			showcaseIndex = pickShowcase(showcaseRNG, showcaseIndex)
+			s := showcases[showcaseIndex]
+			b.clear()
+			b.stamp(s.pattern)
+			window.SetTitle(tr(title) + " - " + tr(s.caption))
+			nextShowcase = time.Now().Add(showcaseInterval)
+		}
+
+		progress := float32(time.Since(lastStep)) / float32(stepInterval)
+		if progress > 1 {
+			progress = 1
+		}
+		if playing != nil || moves != nil {
+			view = moves.at(float64(watch.generation) + float64(progress))
+		} else if *autoFit {
+			// The target is worked out again once a generation, and the
+			// camera eases toward it, most of the way in a quarter second.
+			if fitGeneration != watch.generation || !fitted {
+				fitTarget = view.fit(snapshot(cells))
+				fitGeneration, fitted = watch.generation, true
+			}
+			view = view.toward(fitTarget, 1-math.Exp(-8*time.Since(lastFit).Seconds()))
+		} else if attend != nil {
+			// A slow drift, so the move itself is pleasant to watch.
+			if target, ok := attend.update(meta, time.Now()); ok {
+				view = view.toward(target, 1-math.Exp(-time.Since(lastFit).Seconds()))
+			}
+		}
+		lastFit = time.Now()
+
+		textured := boardRenderer != nil && reference == nil && !*interpolate
+
+		// A dead cell's trail dims through each generation as well as from
+		// one to the next, so it fades out smoothly rather than in steps.
+		trailFade := float32(math.Pow(heatDecay, float64(progress)))
+		trail := func(x, y int) float32 {
+			heat := meta.heat[meta.index(x, y)] * trailFade
+			if !*trails || reference != nil || cells[x][y].state != dead || heat < trailCutoff {
+				return 0
+			}
+			return heat
+		}
+
+		// Diff and interpolation always draw outlines; otherwise live cells
+		// are filled in high-contrast mode.
+		var drawn []cellInstance
+		// Counting neighbors is only worth it for themes that use them.
+		density := activeTheme.gradient.density && !highContrast
+		if textured {
+			boardRenderer.upload(cells, meta, trail, density)
+		} else {
+			for x := range cells {
+				for y, c := range cells[x] {
+					var inst cellInstance
+					var ok bool
+					if reference != nil {
+						inst, ok = c.diffInstance(reference[x][y])
+					} else if *interpolate {
+						inst, ok = c.fadedInstance(previous[x][y], progress)
+					} else {
+						inst, ok = c.instance()
+					}
+					if ok {
+						inst.age = float32(meta.age[meta.index(x, y)])
+						if density {
+							inst.neighbors = float32(c.liveNeighbors(cells))
+						}
+						drawn = append(drawn, inst)
+					} else if heat := trail(x, y); heat > 0 {
+						drawn = append(drawn, cellInstance{x: x, y: y, heat: heat})
+					}
+				}
+			}
+		}
+		solid := (highContrast || filled) && reference == nil && !*interpolate
+
+		var pending, covered []cellInstance
+		for _, e := range edits.pending {
+			pending = append(pending, plain(e.x, e.y))
+		}
+		if obstacles != nil {
+			for _, p := range obstacles.covered {
+				covered = append(covered, plain(p.x, p.y))
+			}
+		}
+
+		drawBoard := func(w *glfw.Window, offset float32) {
+			if showGrid {
+				gridProg := gridShaders.program()
+				gl.UseProgram(gridProg)
+				uniforms(gridProg, w, offset)
+				grid.draw(gridProg)
+			}
+			if textured {
+				boardProg := boardShaders.program(features...)
+				gl.UseProgram(boardProg)
+				uniforms(boardProg, w, offset)
+				boardRenderer.draw(boardProg)
 			}
+			gl.UseProgram(prog)
+			uniforms(prog, w, offset)
+			cellsRenderer.draw(prog, drawn, solid)
+
+			gl.Uniform4f(overlayLocation, 1, 1, 1, 1)
+			cellsRenderer.draw(prog, pending, false)
+
+			gl.Uniform4f(overlayLocation, 0.5, 0.5, 0.55, 1)
+			cellsRenderer.draw(prog, covered, false)
+
+			markers[activeView][boundary].draw(overlayLocation)
+		}
+		if showSpacetime {
+			young, old := activeTheme.gradient.ends()
+			if highContrast {
+				young, old = rgb{1, 1, 1}, rgb{1, 1, 1}
+			}
+			spacetimeProg := spacetimeShaders.program()
+			gl.UseProgram(spacetimeProg)
+			spacetime.draw(spacetimeProg, window, young, old, background)
+		} else {
+			drawBoard(window, viewOffset)
+
+			// The cell under the cursor is highlighted so edits land where
+			// expected.
+			cursorX, cursorY, cursorInside := mapper.Cursor()
+			if x, y, ok := cellAt(cells, cursorX, cursorY, time.Since(start).Seconds()); ok && cursorInside {
+				gl.Uniform4f(overlayLocation, 1, 0.9, 0.2, 1)
+				cellsRenderer.draw(prog, []cellInstance{plain(x, y)}, false)
+			}
+			if keyCursorShown {
+				gl.Uniform4f(overlayLocation, 0.2, 0.9, 1, 1)
+				cellsRenderer.draw(prog, []cellInstance{plain(keyCursor.x, keyCursor.y)}, false)
+			}
+
+			// A pasted pattern shows where it would land: under the mouse, or
+			// under the keyboard cursor while the mouse is away.
+			if carried != nil {
+				at := keyCursor
+				if x, y, ok := cellAt(cells, cursorX, cursorY, time.Since(start).Seconds()); ok && cursorInside {
+					at = point{x, y}
+				}
+				var preview []cellInstance
+				for _, ed := range stampEdits(carried, at.x, at.y) {
+					preview = append(preview, plain(ed.x, ed.y))
+				}
+				gl.Uniform4f(overlayLocation, 0.5, 1, 0.5, 1)
+				cellsRenderer.draw(prog, preview, false)
+			}
+			if track.tracking() {
+				var tracked []cellInstance
+				for _, p := range track.cells {
+					if x, y, ok := onBoard(p); ok {
+						tracked = append(tracked, plain(x, y))
+					}
+				}
+				gl.Uniform4f(overlayLocation, 1, 0.4, 0.8, 1)
+				cellsRenderer.draw(prog, tracked, false)
+			}
+			gl.Uniform4f(overlayLocation, 0, 0, 0, 0)
+		}
+		effects.end()
+
+		// The HUD sits in the main window's top-left corner, larger in
+		// high-contrast mode. The showcase has no generations to count.
+		var hudLines []string
+		if *hud && !splash {
+			hudLines = append(hudLines,
+				fmt.Sprintf(tr("Generation %v"), watch.generation),
+				fmt.Sprintf(tr("Population %v"), b.population()))
+			if track.tracking() {
+				hudLines = append(hudLines, track.summary())
+			}
+			if len(tabs.tabs) > 1 {
+				hudLines = append(hudLines, fmt.Sprintf(tr("Tab %v of %v"), tabs.current+1, len(tabs.tabs)))
+			}
+		}
+		if showPerf {
+			hudLines = append(hudLines, perf.summary)
+		}
+		if len(hudLines) > 0 {
+			scale := textScale(window)
+			if highContrast {
+				scale *= 2
+			}
+			textProg := textShaders.program()
+			gl.UseProgram(textProg)
+			hudText.draw(textProg, hudLines, 4*scale, 4*scale, scale, rgb{0.9, 0.9, 0.9}, background)
+		}
+
+		// Screenshots are of the main window, HUD and all, and are encoded
+		// in the background so the frame isn't held up.
+		if len(screenshots) > 0 {
+			img := screenshot(window)
+			for _, path := range screenshots {
+				path := path
+				go func() {
+					if err := savePNG(path, img); err != nil {
+						log.Println("saving screenshot:", err)
+					} else {
+						log.Println("Saved screenshot to", path)
+					}
+				}()
+			}
+			screenshots = nil
+		}
+
+		// The right half is drawn in the same frame from the same generation,
+		// so the two windows never disagree.
+		if second != nil {
+			second.MakeContextCurrent()
+			activeView = 1
+			effects.begin(second)
+			gl.ClearColor(background[0], background[1], background[2], 1)
+			gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+			drawBoard(second, -viewOffset)
+			gl.Uniform4f(overlayLocation, 0, 0, 0, 0)
+			effects.end()
+			second.SwapBuffers()
+
+			window.MakeContextCurrent()
+			activeView = 0
 		}
+
+		perf.frame(time.Since(frameStarted))
 		mu.Unlock()
 
 		glfw.PollEvents()
 		window.SwapBuffers()
+		pacer.wait()
+	}
+
+	// A session that never got past the showcase, or that played back a
+	// clip, isn't worth coming back to.
+	if !splash && playing == nil {
+		mu.Lock()
+		err := saveSession(b, watch.generation, float64(time.Second)/float64(ticks.interval))
+		mu.Unlock()
+		if err != nil {
+			log.Println("saving session:", err)
+		}
 	}
 
+	if *saveOnExit != "" {
+		mu.Lock()
+		err := saveBoard(*saveOnExit, cells)
+		mu.Unlock()
+		if err != nil {
+			log.Println("saving board:", err)
+		} else {
+			log.Println("Saved board to", *saveOnExit)
+		}
+	}
+}
+
+// setUniforms sets what every program needs to place and color cells in a
+// window showing the board with the given offset, seconds into the run.
+func setUniforms(p uint32, w *glfw.Window, offset, seconds float32, background rgb, gradients *gradientTexture) {
+	gl.Uniform1f(gl.GetUniformLocation(p, gl.Str("u_time\x00")), seconds)
+	gl.Uniform2f(gl.GetUniformLocation(p, gl.Str("u_view\x00")), viewScale, offset)
+	projectionX, projectionY := projection(w.GetSize())
+	gl.Uniform2f(gl.GetUniformLocation(p, gl.Str("u_projection\x00")), projectionX, projectionY)
+	gl.Uniform3f(gl.GetUniformLocation(p, gl.Str("u_camera\x00")), view.X, view.Y, view.Zoom)
+	gradients.bind(p, activeTheme.gradient)
+	gl.Uniform3fv(gl.GetUniformLocation(p, gl.Str("u_background\x00")), 1, &background[0])
+}
+
+// fitViewport sets the viewport of w's context, which must be current, to its
+// whole framebuffer. On HiDPI displays the framebuffer has more pixels than
+// the window has screen coordinates, so the window's size won't do.
+func fitViewport(w *glfw.Window) {
+	framebufferWidth, framebufferHeight := w.GetFramebufferSize()
+	gl.Viewport(0, 0, int32(framebufferWidth), int32(framebufferHeight))
 }
 
 // makeVao initializes and returns a vertex array from the points provided.
@@ -212,10 +1774,52 @@ func compileShader(source string, shaderType uint32) (uint32, error) {
 	return shader, nil
 }
 
-func (c *cell) draw() {
-	if !c.alive {
-		return
+// instance returns how a live cell is drawn, or a decaying one shaded by how
+// far it has decayed, or false if the cell isn't drawn.
+func (c *cell) instance() (cellInstance, bool) {
+	if c.state == dead {
+		return cellInstance{}, false
+	}
+	inst := plain(c.x, c.y)
+	if c.state == live {
+		return inst, true
+	}
+
+	states := activeRule.StateCount()
+	if int(c.state) >= states {
+		return cellInstance{}, false
+	}
+	inst.decay = float32(c.state-1) / float32(states-1)
+	return inst, true
+}
+
+// diffInstance returns the cell colored as a birth or a death if its state
+// differs from wasAlive, or false if it doesn't.
+func (c *cell) diffInstance(wasAlive bool) (cellInstance, bool) {
+	if c.alive() == wasAlive {
+		return cellInstance{}, false
+	}
+	inst := plain(c.x, c.y)
+	inst.diff = 2
+	if c.alive() {
+		inst.diff = 1
+	}
+	return inst, true
+}
+
+// fadedInstance returns the cell partway through the transition from wasAlive
+// to its current state, with progress running from 0 to 1 over a generation,
+// or false if it is dead throughout.
+func (c *cell) fadedInstance(wasAlive bool, progress float32) (cellInstance, bool) {
+	inst := plain(c.x, c.y)
+	switch {
+	case c.alive() && wasAlive:
+	case c.alive():
+		inst.fade = progress
+	case wasAlive:
+		inst.fade = 1 - progress
+	default:
+		return cellInstance{}, false
 	}
-	gl.BindVertexArray(c.drawable)
-	gl.DrawArrays(gl.LINE_LOOP, 0, int32(len(square)/3))
+	return inst, true
 }
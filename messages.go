@@ -0,0 +1,198 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// locale is the language user-facing text is shown in, as a two-letter code.
+// English needs no catalog.
+var locale = "en"
+
+// catalog translates user-facing text, by locale and then by the English
+// text, which doubles as the message ID. Messages with verbs are translated
+// as a whole format string so translators can reorder them.
+var catalog = map[string]map[string]string{
+	"es": {
+		"Conway's Game of Life": "El juego de la vida de Conway",
+		"right half":            "mitad derecha",
+
+		"> %v  (no matching command)":                             "> %v  (ningún comando coincide)",
+		"> %v  [%v]  (%v of %v, Up/Down to choose, Enter to run)": "> %v  [%v]  (%v de %v, Arriba/Abajo para elegir, Intro para ejecutar)",
+
+		"Toggle diff view":            "Alternar vista de diferencias",
+		"Toggle death trails":         "Alternar estelas de células muertas",
+		"Toggle grid lines":           "Alternar líneas de cuadrícula",
+		"Toggle fullscreen":           "Alternar pantalla completa",
+		"Toggle HUD":                  "Alternar indicadores",
+		"Toggle performance counter":  "Alternar contador de rendimiento",
+		"Toggle bloom":                "Alternar resplandor",
+		"Toggle scanlines":            "Alternar líneas de barrido",
+		"Toggle CRT curvature":        "Alternar curvatura CRT",
+		"Toggle 3D history":           "Alternar historia en 3D",
+		"Cycle boundary mode":         "Cambiar modo de borde",
+		"Next rule preset":            "Siguiente regla predefinida",
+		"Warp ahead":                  "Saltar adelante",
+		"Pause or resume":             "Pausar o reanudar",
+		"Step one generation":         "Avanzar una generación",
+		"Step back one generation":    "Retroceder una generación",
+		"Reset to the starting board": "Volver al tablero inicial",
+		"Save board as RLE":           "Guardar el tablero como RLE",
+		"Copy board as RLE":           "Copiar el tablero como RLE",
+		"Bookmark this generation":    "Marcar esta generación",
+		"Jump to a bookmark":          "Saltar a una marca",
+		"Rule: %v":                    "Regla: %v",
+		"Boundary: %v":                "Borde: %v",
+		"Theme: %v":                   "Tema: %v",
+
+		"Stamp a pattern from the library":   "Estampar un patrón de la biblioteca",
+		"Paste a pattern from the clipboard": "Pegar un patrón del portapapeles",
+		"Fit the camera to the live cells":   "Encuadrar las células vivas",
+		"Toggle auto-fit":                    "Alternar encuadre automático",
+
+		"Generation %v": "Generación %v",
+		"Generation %v, %v of %v back  (, and . to scrub, Space to play on from here)": "Generación %v, %v de %v atrás  (, y . para desplazarse, Espacio para seguir desde aquí)",
+		"Population %v": "Población %v",
+		"%.0f fps, %.1f ms/frame (drawing %.1f ms, simulating %.2f ms/generation)": "%.0f fps, %.1f ms/fotograma (dibujo %.1f ms, simulación %.2f ms/generación)",
+
+		"Track the object under the cursor":               "Seguir el objeto bajo el cursor",
+		"Tracking: no period yet":                         "Siguiendo: aún sin periodo",
+		"Tracking: still life":                            "Siguiendo: vida estática",
+		"Tracking: period %v oscillator":                  "Siguiendo: oscilador de periodo %v",
+		"Tracking: %v, moving %v,%v every %v generations": "Siguiendo: %v, se mueve %v,%v cada %v generaciones",
+		"%v orthogonal":                                   "%v ortogonal",
+		"%v diagonal":                                     "%v diagonal",
+		"(%v,%v)c/%v oblique":                             "(%v,%v)c/%v oblicua",
+
+		"New tab":        "Nueva pestaña",
+		"Next tab":       "Pestaña siguiente",
+		"Previous tab":   "Pestaña anterior",
+		"Close tab":      "Cerrar pestaña",
+		"Tab %v of %v":   "Pestaña %v de %v",
+		"Save workspace": "Guardar espacio de trabajo",
+		"Open workspace": "Abrir espacio de trabajo",
+		"Save workspace as: %v  (saved: %v; Enter to save)": "Guardar espacio de trabajo como: %v  (guardados: %v; Intro para guardar)",
+		"Open workspace: %v  (saved: %v; Enter to open)":    "Abrir espacio de trabajo: %v  (guardados: %v; Intro para abrir)",
+
+		"Jump to generation %v, population %v  (%v of %v, Up/Down to choose, Enter to jump)": "Saltar a la generación %v, población %v  (%v de %v, Arriba/Abajo para elegir, Intro para saltar)",
+
+		"Stamp %v  (%v of %v, Up/Down to choose, Enter to place at the cursor)": "Estampar %v  (%v de %v, Arriba/Abajo para elegir, Intro para colocar en el cursor)",
+		"Glider":                "Planeador",
+		"Lightweight spaceship": "Nave ligera",
+		"Gosper glider gun":     "Cañón de planeadores de Gosper",
+		"R-pentomino":           "R-pentominó",
+		"Acorn":                 "Bellota",
+		"Pulsar":                "Púlsar",
+
+		"Gosper glider gun: the first pattern found to grow forever":   "Cañón de planeadores de Gosper: el primer patrón hallado que crece para siempre",
+		"R-pentomino: five cells that take 1103 generations to settle": "R-pentominó: cinco células que tardan 1103 generaciones en estabilizarse",
+		"Pulsar: a period 3 oscillator":                                "Púlsar: un oscilador de periodo 3",
+		"Acorn: a methuselah that grows for 5206 generations":          "Bellota: un matusalén que crece durante 5206 generaciones",
+		"Lightweight spaceship: the smallest orthogonal spaceship":     "Nave ligera: la nave ortogonal más pequeña",
+		"Diehard: vanishes completely after 130 generations":           "Diehard: desaparece por completo tras 130 generaciones",
+		"Glider: the smallest spaceship, moving diagonally":            "Planeador: la nave más pequeña, que avanza en diagonal",
+	},
+	"fr": {
+		"Conway's Game of Life": "Le jeu de la vie de Conway",
+		"right half":            "moitié droite",
+
+		"> %v  (no matching command)":                             "> %v  (aucune commande ne correspond)",
+		"> %v  [%v]  (%v of %v, Up/Down to choose, Enter to run)": "> %v  [%v]  (%v sur %v, Haut/Bas pour choisir, Entrée pour lancer)",
+
+		"Toggle diff view":            "Afficher ou masquer les différences",
+		"Toggle death trails":         "Afficher ou masquer les traînées",
+		"Toggle grid lines":           "Afficher ou masquer la grille",
+		"Toggle fullscreen":           "Basculer en plein écran",
+		"Toggle HUD":                  "Afficher ou masquer les indicateurs",
+		"Toggle performance counter":  "Afficher ou masquer les performances",
+		"Toggle bloom":                "Activer ou désactiver le halo",
+		"Toggle scanlines":            "Activer ou désactiver les lignes de balayage",
+		"Toggle CRT curvature":        "Activer ou désactiver la courbure CRT",
+		"Toggle 3D history":           "Afficher ou masquer l'historique en 3D",
+		"Cycle boundary mode":         "Changer de mode de bord",
+		"Next rule preset":            "Règle prédéfinie suivante",
+		"Warp ahead":                  "Sauter en avant",
+		"Pause or resume":             "Mettre en pause ou reprendre",
+		"Step one generation":         "Avancer d'une génération",
+		"Step back one generation":    "Reculer d'une génération",
+		"Reset to the starting board": "Revenir au plateau de départ",
+		"Save board as RLE":           "Enregistrer le plateau en RLE",
+		"Copy board as RLE":           "Copier le plateau en RLE",
+		"Bookmark this generation":    "Marquer cette génération",
+		"Jump to a bookmark":          "Aller à un marque-page",
+		"Rule: %v":                    "Règle : %v",
+		"Boundary: %v":                "Bord : %v",
+		"Theme: %v":                   "Thème : %v",
+
+		"Stamp a pattern from the library":   "Tamponner un motif de la bibliothèque",
+		"Paste a pattern from the clipboard": "Coller un motif du presse-papiers",
+		"Fit the camera to the live cells":   "Cadrer les cellules vivantes",
+		"Toggle auto-fit":                    "Activer ou désactiver le cadrage automatique",
+
+		"Generation %v": "Génération %v",
+		"Generation %v, %v of %v back  (, and . to scrub, Space to play on from here)": "Génération %v, %v sur %v en arrière  (, et . pour parcourir, Espace pour reprendre d'ici)",
+		"Population %v": "Population %v",
+		"%.0f fps, %.1f ms/frame (drawing %.1f ms, simulating %.2f ms/generation)": "%.0f i/s, %.1f ms/image (dessin %.1f ms, simulation %.2f ms/génération)",
+
+		"Track the object under the cursor":               "Suivre l'objet sous le curseur",
+		"Tracking: no period yet":                         "Suivi : pas encore de période",
+		"Tracking: still life":                            "Suivi : objet stable",
+		"Tracking: period %v oscillator":                  "Suivi : oscillateur de période %v",
+		"Tracking: %v, moving %v,%v every %v generations": "Suivi : %v, se déplace de %v,%v toutes les %v générations",
+		"%v orthogonal":                                   "%v orthogonal",
+		"%v diagonal":                                     "%v diagonal",
+		"(%v,%v)c/%v oblique":                             "(%v,%v)c/%v oblique",
+
+		"New tab":        "Nouvel onglet",
+		"Next tab":       "Onglet suivant",
+		"Previous tab":   "Onglet précédent",
+		"Close tab":      "Fermer l'onglet",
+		"Tab %v of %v":   "Onglet %v sur %v",
+		"Save workspace": "Enregistrer l'espace de travail",
+		"Open workspace": "Ouvrir un espace de travail",
+		"Save workspace as: %v  (saved: %v; Enter to save)": "Enregistrer l'espace de travail sous : %v  (enregistrés : %v ; Entrée pour enregistrer)",
+		"Open workspace: %v  (saved: %v; Enter to open)":    "Ouvrir l'espace de travail : %v  (enregistrés : %v ; Entrée pour ouvrir)",
+
+		"Jump to generation %v, population %v  (%v of %v, Up/Down to choose, Enter to jump)": "Aller à la génération %v, population %v  (%v sur %v, Haut/Bas pour choisir, Entrée pour y aller)",
+
+		"Stamp %v  (%v of %v, Up/Down to choose, Enter to place at the cursor)": "Tamponner %v  (%v sur %v, Haut/Bas pour choisir, Entrée pour placer au curseur)",
+		"Glider":                "Planeur",
+		"Lightweight spaceship": "Vaisseau léger",
+		"Gosper glider gun":     "Canon à planeurs de Gosper",
+		"R-pentomino":           "R-pentomino",
+		"Acorn":                 "Gland",
+		"Pulsar":                "Pulsar",
+
+		"Gosper glider gun: the first pattern found to grow forever":   "Canon à planeurs de Gosper : le premier motif trouvé qui croît sans fin",
+		"R-pentomino: five cells that take 1103 generations to settle": "R-pentomino : cinq cellules qui mettent 1103 générations à se stabiliser",
+		"Pulsar: a period 3 oscillator":                                "Pulsar : un oscillateur de période 3",
+		"Acorn: a methuselah that grows for 5206 generations":          "Gland : un mathusalem qui croît pendant 5206 générations",
+		"Lightweight spaceship: the smallest orthogonal spaceship":     "Vaisseau léger : le plus petit vaisseau orthogonal",
+		"Diehard: vanishes completely after 130 generations":           "Diehard : disparaît entièrement après 130 générations",
+		"Glider: the smallest spaceship, moving diagonally":            "Planeur : le plus petit vaisseau, qui avance en diagonale",
+	},
+}
+
+// tr returns msg in the current locale, or msg itself if it hasn't been
+// translated.
+func tr(msg string) string {
+	if translated, ok := catalog[locale][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// systemLocale returns the language the environment asks for, such as "fr"
+// for LANG=fr_FR.UTF-8, or "en" if it doesn't say.
+func systemLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "C" || value == "POSIX" {
+			continue
+		}
+		if fields := strings.FieldsFunc(value, func(r rune) bool { return r == '_' || r == '.' || r == '-' }); len(fields) > 0 {
+			return strings.ToLower(fields[0])
+		}
+	}
+	return "en"
+}
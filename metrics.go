@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+)
+
+// maxSpaceshipPeriod is the longest period a pattern in spaceshipPatterns may
+// take to come back to its shape for the metrics log to look for it.
+const maxSpaceshipPeriod = 4
+
+// spaceshipPatterns are the spaceships the metrics log looks for, drawn like
+// the showcases. Those that don't fly under the running rule are left out.
+var spaceshipPatterns = []struct {
+	name    string
+	pattern []string
+}{
+	{"glider", []string{
+		".O.",
+		"..O",
+		"OOO",
+	}},
+	{"lwss", []string{
+		".O..O",
+		"O....",
+		"O...O",
+		"OOOO.",
+	}},
+	{"mwss", []string{
+		"...O..",
+		".O...O",
+		"O.....",
+		"O....O",
+		"OOOOO.",
+	}},
+	{"hwss", []string{
+		"...OO..",
+		".O....O",
+		"O......",
+		"O.....O",
+		"OOOOOO.",
+	}},
+}
+
+// notable is one line of the metrics log. Objects is how many objects are on
+// the board, found as the tracker finds them, and a spaceship's line also
+// names it and gives the corner of its bounding box.
+type notable struct {
+	Event      string    `json:"event"`
+	Generation int       `json:"generation"`
+	Population int       `json:"population"`
+	Objects    int       `json:"objects"`
+	Spaceship  string    `json:"spaceship,omitempty"`
+	At         *[2]int   `json:"at,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// metricsLog appends notable events to a file as JSON lines, each stamped
+// with its generation so analysis scripts can line them up with other
+// records of the run: a spaceship appearing, the number of objects changing
+// and the population reaching a new high. A nil metricsLog does nothing.
+type metricsLog struct {
+	enc *json.Encoder
+
+	started    bool
+	record     int
+	objects    int
+	spaceships []sighting
+
+	// shapes names the spaceships flying under rule by their shapes.
+	rule   string
+	shapes map[string]string
+}
+
+type sighting struct {
+	name string
+	at   point
+}
+
+// newMetricsLog returns a log appending to the file at path, or nil if path
+// is empty.
+func newMetricsLog(path string) (*metricsLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsLog{enc: json.NewEncoder(f)}, nil
+}
+
+// observe logs what's notable about the board the watcher last saw, which
+// has moved on by generations. The first board it sees only sets what later
+// ones are compared with. A spaceship is new if there was none of its kind
+// next to it the generation before, so none are after a warp.
+func (l *metricsLog) observe(cells [][]*cell, w *watcher, generations int) {
+	if l == nil {
+		return
+	}
+
+	shapes := l.spaceshipShapes()
+	objects := 0
+	var spaceships []sighting
+	seen := map[point]bool{}
+	for x := range cells {
+		for y, c := range cells[x] {
+			if !c.alive() || seen[point{x, y}] {
+				continue
+			}
+			objects++
+			phase := shapeOf(reach(cells, []point{{x, y}}, seen, columns*rows))
+			if name, ok := shapes[phase.shape]; ok {
+				spaceships = append(spaceships, sighting{name, phase.corner})
+			}
+		}
+	}
+
+	if l.started {
+		if generations == 1 {
+			for _, s := range spaceships {
+				if !s.follows(l.spaceships) {
+					l.write(notable{Event: "spaceship", Spaceship: s.name, At: &[2]int{s.at.x, s.at.y}}, w, objects)
+				}
+			}
+		}
+		if objects != l.objects {
+			l.write(notable{Event: "objects"}, w, objects)
+		}
+		if w.population > l.record {
+			l.write(notable{Event: "record population"}, w, objects)
+		}
+	}
+	if !l.started || w.population > l.record {
+		l.record = w.population
+	}
+	l.started = true
+	l.objects = objects
+	l.spaceships = spaceships
+}
+
+func (l *metricsLog) write(n notable, w *watcher, objects int) {
+	n.Generation, n.Population, n.Objects, n.Time = w.generation, w.population, objects, time.Now()
+	if err := l.enc.Encode(n); err != nil {
+		log.Println("writing metrics log:", err)
+	}
+}
+
+// follows reports whether s could be one of before a generation on, being
+// of the same kind and within two cells of it.
+func (s sighting) follows(before []sighting) bool {
+	for _, b := range before {
+		dx, dy := abs(s.at.x-b.at.x), abs(s.at.y-b.at.y)
+		if boundary == boundaryWrap && dx > columns/2 {
+			dx = columns - dx
+		}
+		if boundary == boundaryWrap && dy > rows/2 {
+			dy = rows - dy
+		}
+		if b.name == s.name && dx <= 2 && dy <= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// spaceshipShapes returns the shapes of the spaceships flying under the
+// running rule, worked out again when the rule changes.
+func (l *metricsLog) spaceshipShapes() map[string]string {
+	name := activeRule.String()
+	if activeLtL != nil {
+		name = activeLtL.String()
+	}
+	if l.shapes != nil && l.rule == name {
+		return l.shapes
+	}
+
+	l.rule, l.shapes = name, map[string]string{}
+	birth, survive := ruleMasks(activeRule)
+	if activeLtL != nil || activeRule.StateCount() > 2 || birth&1 != 0 {
+		return l.shapes
+	}
+	for _, s := range spaceshipPatterns {
+		for _, phase := range spaceshipPhases(s.pattern, birth, survive) {
+			for _, o := range orientations(phase) {
+				l.shapes[shapeOf(o).shape] = s.name
+			}
+		}
+	}
+	return l.shapes
+}
+
+// spaceshipPhases returns every phase of pattern if it comes back to its
+// shape somewhere else within maxSpaceshipPeriod generations under the rule
+// given by birth and survive, or nil if it doesn't fly.
+func spaceshipPhases(pattern []string, birth, survive uint16) [][]point {
+	e := newSparseEngine()
+	for y, row := range pattern {
+		for x, c := range row {
+			if c == 'O' {
+				e.live[point{x, -y}] = true
+			}
+		}
+	}
+
+	var phases [][]point
+	for len(phases) <= maxSpaceshipPeriod && len(e.live) > 0 {
+		var phase []point
+		for p := range e.live {
+			phase = append(phase, p)
+		}
+		if len(phases) > 0 {
+			first, now := shapeOf(phases[0]), shapeOf(phase)
+			if now.shape == first.shape {
+				if now.corner == first.corner {
+					return nil
+				}
+				return phases
+			}
+		}
+		phases = append(phases, phase)
+		e.step(birth, survive)
+	}
+	return nil
+}
+
+// orientations returns cells turned and flipped each of the eight ways.
+func orientations(cells []point) [][]point {
+	var all [][]point
+	for i := 0; i < 8; i++ {
+		turned := make([]point, len(cells))
+		for j, c := range cells {
+			x, y := c.x, c.y
+			if i&1 != 0 {
+				x = -x
+			}
+			if i&2 != 0 {
+				y = -y
+			}
+			if i&4 != 0 {
+				x, y = y, x
+			}
+			turned[j] = point{x, y}
+		}
+		all = append(all, turned)
+	}
+	return all
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+	"testing"
+)
+
+// TestMetricsLog runs a glider into a block on a dead-edged board and checks
+// the events logged on the way.
+func TestMetricsLog(t *testing.T) {
+	savedBoundary := boundary
+	t.Cleanup(func() { boundary = savedBoundary })
+	boundary = boundaryDead
+
+	rows := []string{
+		"............",
+		"..O.........",
+		"...O........",
+		".OOO........",
+		"............",
+		"............",
+		"............",
+		"............",
+		"............",
+		"............",
+		"..........OO",
+		"..........OO",
+	}
+	useRule(t, "B3/S23", len(rows[0]), len(rows))
+
+	var out bytes.Buffer
+	l := &metricsLog{enc: json.NewEncoder(&out)}
+	b := &board{cells: renderCells(rows), engine: naiveEngine{}}
+	var w watcher
+	w.population = population(b.cells)
+	l.observe(b.cells, &w, 0)
+	if out.Len() != 0 {
+		t.Errorf("the first board was logged: %v", out.String())
+	}
+	if l.objects != 2 || len(l.spaceships) != 1 || l.spaceships[0].name != "glider" {
+		t.Fatalf("found %v objects and spaceships %v, want 2 objects and a glider", l.objects, l.spaceships)
+	}
+
+	for i := 0; i < 40; i++ {
+		b.Step()
+		w.observe(b.cells, nil, 1)
+		l.observe(b.cells, &w, 1)
+	}
+	var events []string
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var n notable
+		if err := dec.Decode(&n); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, n.Event)
+	}
+	// The glider hits the block, and what it leaves gives fewer objects.
+	if len(events) == 0 || events[0] != "objects" {
+		t.Errorf("logged %v, want the glider hitting the block first", events)
+	}
+	for _, e := range events {
+		if e == "spaceship" {
+			t.Errorf("the glider was logged as a new spaceship while flying: %v", events)
+			break
+		}
+	}
+}
+
+// TestSpaceshipPhases checks that the spaceships fly under rules where they
+// should, and don't under one where they can't.
+func TestSpaceshipPhases(t *testing.T) {
+	for _, c := range []struct {
+		rule   string
+		phases int
+	}{
+		{"B3/S23", 4},
+		{"B3/S012345678", 0},
+	} {
+		r, err := life.LookupRule(c.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		birth, survive := ruleMasks(r)
+		for _, s := range spaceshipPatterns {
+			if got := len(spaceshipPhases(s.pattern, birth, survive)); got != c.phases {
+				t.Errorf("%v under %v: %v phases, want %v", s.name, c.rule, got, c.phases)
+			}
+		}
+	}
+}
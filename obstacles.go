@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+)
+
+// obstacle is a set of cells that hold every cell they cover dead, or alive
+// if it is solid, so patterns have a landscape to run into. A wall is a line
+// that slides along at a fixed velocity, wrapping around the board; a
+// barrier is a line that spins around its pivot. A maze or a cave fills the
+// whole board with walls that stay put.
+type obstacle struct {
+	Kind  string `json:"kind"` // "wall", "barrier", "maze" or "cave"
+	Solid bool   `json:"solid"`
+
+	// X and Y are where a wall starts at generation 0, or a barrier's pivot.
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Length int `json:"length"`
+
+	// A wall runs Length cells up, or right if Horizontal, and moves DX, DY
+	// cells per generation.
+	Horizontal bool    `json:"horizontal"`
+	DX         float64 `json:"dx"`
+	DY         float64 `json:"dy"`
+
+	// A barrier reaches Length cells either side of its pivot, starting at
+	// Angle degrees and turning Turn degrees per generation.
+	Angle float64 `json:"angle"`
+	Turn  float64 `json:"turn"`
+
+	// A maze has corridors Length cells wide, 3 if Length is 0. A cave
+	// starts with Fill of its cells walled, 0.45 if Fill is 0, and is
+	// smoothed Smooth times, 5 if Smooth is 0. Both are laid out from Seed,
+	// or from the board's seed if Seed is 0.
+	Fill   float64 `json:"fill"`
+	Smooth int     `json:"smooth"`
+	Seed   int64   `json:"seed"`
+}
+
+// fixed reports whether o covers the same cells every generation.
+func (o obstacle) fixed() bool {
+	return o.Kind == "maze" || o.Kind == "cave"
+}
+
+// cover returns the cells o covers at generation that are on the board.
+func (o obstacle) cover(generation, columns, rows int) []point {
+	var covered []point
+	g := float64(generation)
+
+	switch o.Kind {
+	case "wall":
+		x0 := int(math.Round(float64(o.X) + o.DX*g))
+		y0 := int(math.Round(float64(o.Y) + o.DY*g))
+		for i := 0; i < o.Length; i++ {
+			x, y := x0, y0+i
+			if o.Horizontal {
+				x, y = x0+i, y0
+			}
+			covered = append(covered, point{(x%columns + columns) % columns, (y%rows + rows) % rows})
+		}
+	case "barrier":
+		angle := (o.Angle + o.Turn*g) * math.Pi / 180
+		for i := -o.Length; i <= o.Length; i++ {
+			x := o.X + int(math.Round(float64(i)*math.Cos(angle)))
+			y := o.Y + int(math.Round(float64(i)*math.Sin(angle)))
+			if x >= 0 && x < columns && y >= 0 && y < rows {
+				covered = append(covered, point{x, y})
+			}
+		}
+	case "maze":
+		covered = o.maze(columns, rows)
+	case "cave":
+		covered = o.cave(columns, rows)
+	}
+	return covered
+}
+
+func (o obstacle) rand() *rand.Rand {
+	s := o.Seed
+	if s == 0 {
+		s = seed
+	}
+	return rand.New(rand.NewSource(s))
+}
+
+// maze returns the walls of a maze with one path between any two of its
+// corridors, carved by a depth-first search from the bottom left. Corridors
+// and the one-cell walls between them repeat every width+1 cells, and the
+// part of the board past the last whole corridor is wall.
+func (o obstacle) maze(columns, rows int) []point {
+	width := o.Length
+	if width <= 0 {
+		width = 3
+	}
+	step := width + 1
+	mazeColumns, mazeRows := (columns-1)/step, (rows-1)/step
+
+	open := make([][]bool, columns)
+	for x := range open {
+		open[x] = make([]bool, rows)
+	}
+	// carve opens the width by height cells with x, y at their bottom left.
+	carve := func(x, y, w, h int) {
+		for i := x; i < x+w; i++ {
+			for j := y; j < y+h; j++ {
+				open[i][j] = true
+			}
+		}
+	}
+
+	if mazeColumns > 0 && mazeRows > 0 {
+		r := o.rand()
+		visited := make([][]bool, mazeColumns)
+		for mx := range visited {
+			visited[mx] = make([]bool, mazeRows)
+		}
+		visited[0][0] = true
+		carve(1, 1, width, width)
+
+		stack := []point{{0, 0}}
+		for len(stack) > 0 {
+			at := stack[len(stack)-1]
+			var next []point
+			for _, d := range []point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				n := point{at.x + d.x, at.y + d.y}
+				if n.x >= 0 && n.x < mazeColumns && n.y >= 0 && n.y < mazeRows && !visited[n.x][n.y] {
+					next = append(next, n)
+				}
+			}
+			if len(next) == 0 {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			n := next[r.Intn(len(next))]
+			visited[n.x][n.y] = true
+			carve(1+n.x*step, 1+n.y*step, width, width)
+			// The wall between them is the line of cells one step from
+			// the lower of the two.
+			lo := at
+			if n.x < lo.x || n.y < lo.y {
+				lo = n
+			}
+			if n.x != at.x {
+				carve((lo.x+1)*step, 1+lo.y*step, 1, width)
+			} else {
+				carve(1+lo.x*step, (lo.y+1)*step, width, 1)
+			}
+			stack = append(stack, n)
+		}
+	}
+
+	return closed(open)
+}
+
+// cave returns the walls of a cave grown by cellular automaton: cells start
+// walled at random, then each pass walls every cell with more than four
+// walled neighbors and opens every cell with fewer, counting off the board
+// as walled, which joins up the walls and rounds off the openings.
+func (o obstacle) cave(columns, rows int) []point {
+	fill, passes := o.Fill, o.Smooth
+	if fill <= 0 {
+		fill = 0.45
+	}
+	if passes <= 0 {
+		passes = 5
+	}
+
+	r := o.rand()
+	open := make([][]bool, columns)
+	for x := range open {
+		open[x] = make([]bool, rows)
+		for y := range open[x] {
+			open[x][y] = r.Float64() >= fill
+		}
+	}
+
+	for pass := 0; pass < passes; pass++ {
+		next := make([][]bool, columns)
+		for x := range next {
+			next[x] = make([]bool, rows)
+			for y := range next[x] {
+				walls := 0
+				for dx := -1; dx <= 1; dx++ {
+					for dy := -1; dy <= 1; dy++ {
+						nx, ny := x+dx, y+dy
+						if (dx != 0 || dy != 0) && (nx < 0 || nx >= columns || ny < 0 || ny >= rows || !open[nx][ny]) {
+							walls++
+						}
+					}
+				}
+				next[x][y] = walls < 4 || walls == 4 && open[x][y]
+			}
+		}
+		open = next
+	}
+
+	return closed(open)
+}
+
+// closed returns the cells that aren't open.
+func closed(open [][]bool) []point {
+	var walls []point
+	for x := range open {
+		for y, isOpen := range open[x] {
+			if !isOpen {
+				walls = append(walls, point{x, y})
+			}
+		}
+	}
+	return walls
+}
+
+// obstacles are the obstacles on the board. A nil *obstacles has none.
+type obstacles struct {
+	list []obstacle
+
+	// fixed holds the cells of each maze and cave in list, once laid out.
+	fixed [][]point
+
+	// covered is what apply last held in place, for drawing.
+	covered []point
+}
+
+// loadObstacles reads a JSON list of obstacles from path, or returns nil if
+// path is empty.
+func loadObstacles(path string) (*obstacles, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list []obstacle
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	for i, o := range list {
+		switch o.Kind {
+		case "wall", "barrier", "maze", "cave":
+		default:
+			return nil, fmt.Errorf("reading %v: obstacle %d is a %q, want wall, barrier, maze or cave", path, i, o.Kind)
+		}
+		if o.Fill < 0 || o.Fill >= 1 {
+			return nil, fmt.Errorf("reading %v: obstacle %d has fill %v, want it from 0 up to 1", path, i, o.Fill)
+		}
+	}
+	return &obstacles{list: list, fixed: make([][]point, len(list))}, nil
+}
+
+// apply holds the cells each obstacle covers at generation.
+func (o *obstacles) apply(cells [][]*cell, generation int) {
+	if o == nil {
+		return
+	}
+
+	o.covered = o.covered[:0]
+	for i, ob := range o.list {
+		covers := o.fixed[i]
+		if covers == nil {
+			covers = ob.cover(generation, len(cells), len(cells[0]))
+			if ob.fixed() {
+				o.fixed[i] = covers
+			}
+		}
+		for _, p := range covers {
+			cells[p.x][p.y].set(ob.Solid)
+			o.covered = append(o.covered, p)
+		}
+	}
+}
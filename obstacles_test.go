@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMaze checks that every open cell of a maze can be reached from every
+// other, on boards that don't divide into whole corridors.
+func TestMaze(t *testing.T) {
+	for _, size := range [][2]int{{40, 30}, {37, 53}} {
+		columns, rows := size[0], size[1]
+		for _, width := range []int{1, 3, 5} {
+			o := obstacle{Kind: "maze", Length: width, Seed: 9}
+			open := make([][]bool, columns)
+			for x := range open {
+				open[x] = make([]bool, rows)
+				for y := range open[x] {
+					open[x][y] = true
+				}
+			}
+			walls := o.cover(0, columns, rows)
+			for _, p := range walls {
+				open[p.x][p.y] = false
+			}
+			if !open[1][1] || len(walls) == columns*rows {
+				t.Fatalf("%vx%v width %v: the maze has no corridors", columns, rows, width)
+			}
+
+			// Flood from the first corridor, then look for anything missed.
+			reached := map[point]bool{{1, 1}: true}
+			queue := []point{{1, 1}}
+			for len(queue) > 0 {
+				at := queue[0]
+				queue = queue[1:]
+				for _, d := range []point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+					n := point{at.x + d.x, at.y + d.y}
+					if n.x >= 0 && n.x < columns && n.y >= 0 && n.y < rows && open[n.x][n.y] && !reached[n] {
+						reached[n] = true
+						queue = append(queue, n)
+					}
+				}
+			}
+			for x := range open {
+				for y := range open[x] {
+					if open[x][y] && !reached[point{x, y}] {
+						t.Fatalf("%vx%v width %v: %v,%v is cut off", columns, rows, width, x, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestCave checks that a cave is laid out the same way from the same seed,
+// and leaves room to move.
+func TestCave(t *testing.T) {
+	o := obstacle{Kind: "cave", Seed: 4}
+	walls := o.cover(0, 60, 40)
+	if again := o.cover(10, 60, 40); !reflect.DeepEqual(walls, again) {
+		t.Errorf("the cave changed between generations")
+	}
+	if len(walls) == 0 || len(walls) > 60*40*3/4 {
+		t.Errorf("%v of %v cells are walled", len(walls), 60*40)
+	}
+}
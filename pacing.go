@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"time"
+)
+
+// defaultRefreshRate is assumed when no monitor reports a refresh rate.
+const defaultRefreshRate = 60
+
+// framePacer sleeps out the rest of each frame so the render loop doesn't spin
+// faster than the display can show frames, even when vsync is ignored. A nil
+// *framePacer doesn't wait at all, for running as fast as possible.
+type framePacer struct {
+	interval time.Duration
+	next     time.Time
+}
+
+// newFramePacer paces frames at fps, or returns nil if fps is 0.
+func newFramePacer(fps int) *framePacer {
+	if fps == 0 {
+		return nil
+	}
+	return &framePacer{
+		interval: time.Second / time.Duration(fps),
+		next:     time.Now(),
+	}
+}
+
+// wait blocks until the next frame is due.
+func (p *framePacer) wait() {
+	if p == nil {
+		return
+	}
+	p.next = p.next.Add(p.interval)
+
+	now := time.Now()
+	if p.next.Before(now) {
+		// We've fallen behind; start pacing again from here rather than
+		// rushing to catch up.
+		p.next = now
+		return
+	}
+	time.Sleep(p.next.Sub(now))
+}
+
+// refreshRate returns the refresh rate of the monitor the window is mostly on.
+func refreshRate(window *glfw.Window) int {
+	m := monitorFor(window)
+	if m == nil {
+		return defaultRefreshRate
+	}
+	if mode := m.GetVideoMode(); mode != nil && mode.RefreshRate > 0 {
+		return mode.RefreshRate
+	}
+	return defaultRefreshRate
+}
+
+// monitorFor returns the monitor containing the center of the window, falling
+// back to the primary monitor.
+func monitorFor(window *glfw.Window) *glfw.Monitor {
+	if m := window.GetMonitor(); m != nil {
+		return m
+	}
+
+	wx, wy := window.GetPos()
+	ww, wh := window.GetSize()
+	cx, cy := wx+ww/2, wy+wh/2
+
+	for _, m := range glfw.GetMonitors() {
+		mx, my := m.GetPos()
+		mode := m.GetVideoMode()
+		if mode == nil {
+			continue
+		}
+		if cx >= mx && cx < mx+mode.Width && cy >= my && cy < my+mode.Height {
+			return m
+		}
+	}
+	return glfw.GetPrimaryMonitor()
+}
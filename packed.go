@@ -0,0 +1,213 @@
+package main
+
+// packedEngine keeps the board as rows of bits, 64 cells to a word, and works
+// out a whole word of cells at a time with bitwise adders instead of visiting
+// each cell. It handles two-state B/S rules under every boundary mode and
+// falls back to the naive engine otherwise.
+//
+// The packed rows are the board from one advance to the next. The cells are
+// only read back in when something else has written to them, and only the
+// words that changed are written out to them for drawing.
+type packedEngine struct {
+	// current and next are the board a row at a time: bit x%64 of word x/64
+	// in row y is cells[x][y]. Bits past the last column are always 0.
+	current, next [][]uint64
+
+	// shown is what the cells hold, as of the last unpack. first and synced
+	// are the board's first cell and cellWrites then, to tell whether the
+	// cells have been written to or swapped for another board since.
+	shown  [][]uint64
+	first  *cell
+	synced uint64
+}
+
+// reset does nothing, since cellWrites already tells the engine to read the
+// board back in.
+func (p *packedEngine) reset() {}
+
+func (p *packedEngine) advance(cells [][]*cell, k int) {
+	if activeLtL != nil || activeRule.StateCount() > 2 {
+		naiveEngine{}.advance(cells, k)
+		return
+	}
+
+	if p.first != cells[0][0] || p.synced != cellWrites {
+		p.pack(cells)
+	}
+	birth, survive := ruleMasks(activeRule)
+	for i := 0; i < 1<<k; i++ {
+		inBands(len(p.current), func(y0, y1 int) {
+			for y := y0; y < y1; y++ {
+				p.stepRow(y, len(cells), birth, survive)
+			}
+		})
+		p.current, p.next = p.next, p.current
+	}
+	p.unpack(cells)
+}
+
+// pack copies the board into current, resizing the buffers if the board has
+// changed size since the last call.
+func (p *packedEngine) pack(cells [][]*cell) {
+	columns, rows := len(cells), len(cells[0])
+	words := (columns + 63) / 64
+	if len(p.current) != rows || len(p.current[0]) != words {
+		p.current = makeBitRows(rows, words)
+		p.next = makeBitRows(rows, words)
+		p.shown = makeBitRows(rows, words)
+	}
+
+	for y, row := range p.current {
+		for i := range row {
+			row[i] = 0
+		}
+		for x := 0; x < columns; x++ {
+			if cells[x][y].alive() {
+				row[x/64] |= 1 << uint(x%64)
+			}
+		}
+		copy(p.shown[y], row)
+	}
+	p.first = cells[0][0]
+}
+
+// unpack writes the cells of every word that differs from what they hold.
+// It sets them directly rather than with set, since the packed rows already
+// match them afterwards.
+func (p *packedEngine) unpack(cells [][]*cell) {
+	for y, row := range p.current {
+		for i, word := range row {
+			if word == p.shown[y][i] {
+				continue
+			}
+			for x := i * 64; x < len(cells) && x < (i+1)*64; x++ {
+				c := cells[x][y]
+				c.state = dead
+				if word&(1<<uint(x%64)) != 0 {
+					c.state = live
+				}
+				c.stateNext = c.state
+			}
+			p.shown[y][i] = word
+		}
+	}
+	p.synced = cellWrites
+}
+
+func makeBitRows(rows, words int) [][]uint64 {
+	bits := make([][]uint64, rows)
+	for y := range bits {
+		bits[y] = make([]uint64, words)
+	}
+	return bits
+}
+
+// ruleMasks turns a rule into bit sets of neighbor counts: bit n of birth is
+// set if a dead cell with n live neighbors is born, and likewise for survive.
+func ruleMasks(r rule) (birth, survive uint16) {
+	for _, n := range r.Birth {
+		birth |= 1 << uint(n)
+	}
+	for _, n := range r.Survive {
+		survive |= 1 << uint(n)
+	}
+	return birth, survive
+}
+
+// stepRow works out row y of the next generation into p.next.
+func (p *packedEngine) stepRow(y, columns int, birth, survive uint16) {
+	rows := len(p.current)
+	row := p.current[y]
+	var above, below []uint64
+	if _, ya, ok := boundary.resolve(0, y+1, columns, rows); ok {
+		above = p.current[ya]
+	}
+	if _, yb, ok := boundary.resolve(0, y-1, columns, rows); ok {
+		below = p.current[yb]
+	}
+
+	out := p.next[y]
+	for i := range out {
+		// The neighbor counts of 64 cells at once, as four bit planes:
+		// count bit j of cell b is bit b of planes[j].
+		var planes [4]uint64
+		add := func(a uint64) {
+			for j := 0; j < 3 && a != 0; j++ {
+				planes[j], a = planes[j]^a, planes[j]&a
+			}
+			planes[3] |= a
+		}
+
+		add(shiftWest(row, i, columns))
+		add(shiftEast(row, i, columns))
+		if above != nil {
+			add(above[i])
+			add(shiftWest(above, i, columns))
+			add(shiftEast(above, i, columns))
+		}
+		if below != nil {
+			add(below[i])
+			add(shiftWest(below, i, columns))
+			add(shiftEast(below, i, columns))
+		}
+
+		var next uint64
+		for n := uint(0); n <= 8; n++ {
+			var take uint64
+			if birth&(1<<n) != 0 {
+				take |= ^row[i]
+			}
+			if survive&(1<<n) != 0 {
+				take |= row[i]
+			}
+			if take == 0 {
+				continue
+			}
+			for j, plane := range planes {
+				if n&(1<<uint(j)) == 0 {
+					plane = ^plane
+				}
+				take &= plane
+			}
+			next |= take
+		}
+		out[i] = next & columnMask(i, columns)
+	}
+}
+
+// shiftWest returns word i of row moved one cell to the east, so that each
+// cell lines up with its neighbor to the west.
+func shiftWest(row []uint64, i, columns int) uint64 {
+	w := row[i] << 1
+	if i > 0 {
+		w |= row[i-1] >> 63
+	} else if x, _, ok := boundary.resolve(-1, 0, columns, 1); ok {
+		w |= row[x/64] >> uint(x%64) & 1
+	}
+	return w
+}
+
+// shiftEast returns word i of row moved one cell to the west, so that each
+// cell lines up with its neighbor to the east.
+func shiftEast(row []uint64, i, columns int) uint64 {
+	w := row[i] >> 1
+	if i+1 < len(row) {
+		w |= row[i+1] << 63
+	}
+
+	// The last column's eastern neighbor is past the edge of the board.
+	if i == (columns-1)/64 {
+		if x, _, ok := boundary.resolve(columns, 0, columns, 1); ok {
+			w |= (row[x/64] >> uint(x%64) & 1) << uint((columns-1)%64)
+		}
+	}
+	return w
+}
+
+// columnMask has a bit set for every cell of word i that is on the board.
+func columnMask(i, columns int) uint64 {
+	if rest := columns - i*64; rest < 64 {
+		return 1<<uint(rest) - 1
+	}
+	return ^uint64(0)
+}
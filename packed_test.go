@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+	"testing"
+)
+
+// TestPackedMatchesNaive runs soups on the packed and naive engines side by
+// side, under each kind of rule and each boundary, on boards whose width
+// isn't a multiple of 64, and checks the boards stay the same, including
+// after cells are set from outside the engine between steps.
+func TestPackedMatchesNaive(t *testing.T) {
+	savedBoundary := boundary
+	t.Cleanup(func() { boundary = savedBoundary })
+
+	for _, rule := range []string{"B3/S23", "briansbrain", "B03/S23"} {
+		for _, mode := range []boundaryMode{boundaryWrap, boundaryDead, boundaryMirror} {
+			for _, size := range [][2]int{{1, 5}, {63, 9}, {65, 12}, {130, 17}} {
+				width, height := size[0], size[1]
+				useRule(t, rule, width, height)
+				boundary = mode
+
+				alive := life.Fill(int64(width*height), 0.3, width*height)
+				newCells := func() [][]*cell {
+					cells := make([][]*cell, width)
+					for x := range cells {
+						for y := 0; y < height; y++ {
+							c := newCell(x, y)
+							c.set(alive[x*height+y])
+							cells[x] = append(cells[x], c)
+						}
+					}
+					return cells
+				}
+				packed := &board{cells: newCells(), engine: &packedEngine{}}
+				naive := &board{cells: newCells(), engine: naiveEngine{}}
+
+				for i := 0; i < 24; i++ {
+					if i%5 == 2 {
+						// The last column, and those either side of a word
+						// boundary where the board is wide enough.
+						for _, x := range []int{width - 1, 63, 64} {
+							if x < width {
+								y := i % height
+								packed.cells[x][y].set(!packed.cells[x][y].alive())
+								naive.cells[x][y].set(!naive.cells[x][y].alive())
+							}
+						}
+					}
+					if i%7 == 6 {
+						packed.Warp(2)
+						naive.Warp(2)
+					} else {
+						packed.Step()
+						naive.Step()
+					}
+					if x, y, ok := firstDifference(packed.cells, naive.cells); ok {
+						t.Errorf("%v, %v, %vx%v: after step %v, packed has %v at %v,%v and naive %v",
+							rule, mode, width, height, i+1, packed.cells[x][y].state, x, y, naive.cells[x][y].state)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// firstDifference returns the first cell whose state differs between a and
+// b, or false if they're the same.
+func firstDifference(a, b [][]*cell) (int, int, bool) {
+	for x := range a {
+		for y := range a[x] {
+			if a[x][y].state != b[x][y].state {
+				return x, y, true
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/jake-shasteen/golang-gl-conway-life/input"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// command is something the palette can run.
+type command struct {
+	name string
+	run  func()
+}
+
+// palette is a fuzzy-searchable list of commands, opened with Ctrl+P. There
+// is no text rendering, so it draws itself in the window title.
+type palette struct {
+	commands []command
+
+	open     bool
+	query    string
+	selected int
+}
+
+// match is a command along with how well it matches the query.
+type match struct {
+	command
+	score int
+}
+
+// matches returns the commands matching the query, best first.
+func (p *palette) matches() []match {
+	var found []match
+	for _, c := range p.commands {
+		if score, ok := fuzzyScore(p.query, c.name); ok {
+			found = append(found, match{c, score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].score > found[j].score
+	})
+	return found
+}
+
+// fuzzyScore reports whether the letters of query appear in order in name,
+// ignoring case, and scores the match higher the more of them are adjacent or
+// start a word.
+func fuzzyScore(query, name string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	n := []rune(strings.ToLower(name))
+
+	score, qi := 0, 0
+	for ni := 0; ni < len(n) && qi < len(q); ni++ {
+		if n[ni] != q[qi] {
+			continue
+		}
+		score++
+		if ni == 0 || !unicode.IsLetter(n[ni-1]) {
+			score += 2
+		}
+		if qi > 0 && ni > 0 && n[ni-1] == q[qi-1] {
+			score++
+		}
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+// handle applies one piece of typed input and reports whether the palette is
+// still open afterwards, and the command chosen, if any. The caller runs it
+// once it has given up the keyboard, since the command may want it.
+func (p *palette) handle(t input.Text) (open bool, run func()) {
+	switch {
+	case t.Key == glfw.KeyEscape:
+		p.open = false
+	case t.Key == glfw.KeyEnter || t.Key == glfw.KeyKPEnter:
+		p.open = false
+		if found := p.matches(); p.selected < len(found) {
+			run = found[p.selected].run
+		}
+	case t.Key == glfw.KeyBackspace:
+		if r := []rune(p.query); len(r) > 0 {
+			p.query = string(r[:len(r)-1])
+		}
+		p.selected = 0
+	case t.Key == glfw.KeyDown || t.Key == glfw.KeyTab:
+		p.selected++
+	case t.Key == glfw.KeyUp:
+		p.selected--
+	case t.Rune != 0:
+		p.query += string(t.Rune)
+		p.selected = 0
+	}
+
+	if n := len(p.matches()); n == 0 {
+		p.selected = 0
+	} else {
+		p.selected = (p.selected + n) % n
+	}
+	return p.open, run
+}
+
+// title describes the palette's state for the window title.
+func (p *palette) title() string {
+	found := p.matches()
+	if len(found) == 0 {
+		return fmt.Sprintf(tr("> %v  (no matching command)"), p.query)
+	}
+	return fmt.Sprintf(tr("> %v  [%v]  (%v of %v, Up/Down to choose, Enter to run)"),
+		p.query, found[p.selected].name, p.selected+1, len(found))
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// strict is set by --strict, for runs that must come out the same every
+// time: the board is seeded without floating point and every generation is
+// worked out on one goroutine.
+var strict bool
+
+// inBands splits rows 0 to rows-1 into one horizontal band per CPU and calls
+// fn on each band concurrently, returning once every band is done. fn gets
+// the band's rows as y0 <= y < y1.
+//
+// Bands can read each other's rows freely as long as they only write their
+// own, which the engines guarantee by working out the next generation into
+// a separate buffer from the current one.
+func inBands(rows int, fn func(y0, y1 int)) {
+	bands := runtime.NumCPU()
+	if bands > rows {
+		bands = rows
+	}
+	if bands <= 1 || strict {
+		fn(0, rows)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < bands; i++ {
+		wg.Add(1)
+		go func(y0, y1 int) {
+			defer wg.Done()
+			fn(y0, y1)
+		}(i*rows/bands, (i+1)*rows/bands)
+	}
+	wg.Wait()
+}
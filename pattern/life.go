@@ -0,0 +1,120 @@
+package pattern
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readLife106 reads Life 1.06: a header line, then one live cell per line as
+// "x y", with y running down.
+func readLife106(r io.Reader) (*Pattern, error) {
+	scanner := bufio.NewScanner(r)
+	var cells [][2]int
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var x, y int
+		if _, err := fmt.Sscan(line, &x, &y); err != nil {
+			return nil, fmt.Errorf("bad Life 1.06 cell %q", line)
+		}
+		cells = append(cells, [2]int{x, y})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return FromCells(cells), nil
+}
+
+// readLife105 reads Life 1.05: blocks of rows of . and *, each placed by a
+// "#P x y" line giving its top-left corner, with y running down. "#N" means
+// Conway's rule and "#R" gives one as survival/birth, like 23/3.
+func readLife105(r io.Reader) (*Pattern, error) {
+	scanner := bufio.NewScanner(r)
+	var cells [][2]int
+	var rule string
+	x0, y := 0, 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#P"):
+			if _, err := fmt.Sscan(line[2:], &x0, &y); err != nil {
+				return nil, fmt.Errorf("bad Life 1.05 block position %q", line)
+			}
+		case strings.HasPrefix(line, "#N"):
+			rule = "B3/S23"
+		case strings.HasPrefix(line, "#R"):
+			sb := strings.SplitN(strings.TrimSpace(line[2:]), "/", 2)
+			if len(sb) != 2 {
+				return nil, fmt.Errorf("bad Life 1.05 rule %q", line)
+			}
+			rule = "B" + sb[1] + "/S" + sb[0]
+		case strings.HasPrefix(line, "#"):
+			// Descriptions and the header.
+		default:
+			for x, ch := range line {
+				if ch == '*' {
+					cells = append(cells, [2]int{x0 + x, y})
+				}
+			}
+			y++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	p := FromCells(cells)
+	p.Rule = rule
+	return p, nil
+}
+
+// WriteLife106 writes the pattern in Life 1.06, with its top-left corner at
+// 0, 0. The format has no way to give the rule.
+func (p *Pattern) WriteLife106(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#Life 1.06")
+	for _, c := range p.cells() {
+		fmt.Fprintf(bw, "%d %d\n", c[0], c[1])
+	}
+	return bw.Flush()
+}
+
+// WriteLife105 writes the pattern in Life 1.05, as one block at 0, 0. The
+// rule goes in a #N or #R line if it is a two-state B/S rule.
+func (p *Pattern) WriteLife105(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#Life 1.05")
+
+	rule := strings.ToUpper(p.Rule)
+	bs := strings.Split(rule, "/")
+	switch {
+	case rule == "B3/S23":
+		fmt.Fprintln(bw, "#N")
+	case len(bs) == 2 && strings.HasPrefix(bs[0], "B") && strings.HasPrefix(bs[1], "S") &&
+		strings.Trim(bs[0][1:]+bs[1][1:], "012345678") == "":
+		fmt.Fprintf(bw, "#R %v/%v\n", bs[1][1:], bs[0][1:])
+	}
+
+	fmt.Fprintln(bw, "#P 0 0")
+	for _, row := range p.Rows {
+		// Blank lines don't count as rows, so an empty row is a lone dot.
+		line := strings.TrimRight(strings.Map(func(ch rune) rune {
+			if ch == 'O' {
+				return '*'
+			}
+			return '.'
+		}, row), ".")
+		if line == "" {
+			line = "."
+		}
+		fmt.Fprintln(bw, line)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,117 @@
+// Package pattern reads and writes Life patterns in the common file formats:
+// RLE, plaintext, and Life 1.05 and 1.06, and as PNG pictures.
+package pattern
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Pattern is a pattern as rows of text, top row first, with O for a live cell
+// and anything else for a dead one.
+type Pattern struct {
+	Rows []string
+
+	// Rule is the rule the file says the pattern runs under, as written in
+	// the file, or "" if it doesn't say.
+	Rule string
+
+	// Generation is the generation the pattern was saved at, which only PNG
+	// files keep, or 0.
+	Generation int
+}
+
+// Load reads the pattern at path, telling the format from its first line:
+// "#Life 1.05" or "#Life 1.06", PNG from its signature, plaintext if it
+// starts with !, . or O, and RLE otherwise.
+func Load(path string) (*Pattern, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := Read(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	return p, nil
+}
+
+// Read reads a pattern in any format Load understands.
+func Read(r io.Reader) (*Pattern, error) {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(10)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if strings.HasPrefix(string(first), pngSignature) {
+		return ReadPNG(br)
+	}
+	switch strings.ToLower(string(first)) {
+	case "#life 1.05":
+		return readLife105(br)
+	case "#life 1.06":
+		return readLife106(br)
+	}
+	if len(first) > 0 && strings.IndexByte("!.O", first[0]) >= 0 {
+		return readPlaintext(br)
+	}
+	return ReadRLE(br)
+}
+
+// FromCells lays out the live cells, given as x, y with y down, as rows
+// cropped to their bounding box.
+func FromCells(cells [][2]int) *Pattern {
+	if len(cells) == 0 {
+		return &Pattern{}
+	}
+
+	minX, minY, maxX, maxY := cells[0][0], cells[0][1], cells[0][0], cells[0][1]
+	for _, c := range cells {
+		if c[0] < minX {
+			minX = c[0]
+		}
+		if c[0] > maxX {
+			maxX = c[0]
+		}
+		if c[1] < minY {
+			minY = c[1]
+		}
+		if c[1] > maxY {
+			maxY = c[1]
+		}
+	}
+
+	grid := make([][]byte, maxY-minY+1)
+	for y := range grid {
+		grid[y] = bytes.Repeat([]byte{'.'}, maxX-minX+1)
+	}
+	for _, c := range cells {
+		grid[c[1]-minY][c[0]-minX] = 'O'
+	}
+
+	p := &Pattern{Rows: make([]string, len(grid))}
+	for y, row := range grid {
+		p.Rows[y] = string(row)
+	}
+	return p
+}
+
+// cells returns the live cells of the pattern as x, y with y down, from the
+// top-left corner of its rows.
+func (p *Pattern) cells() [][2]int {
+	var cells [][2]int
+	for y, row := range p.Rows {
+		for x, ch := range row {
+			if ch == 'O' {
+				cells = append(cells, [2]int{x, y})
+			}
+		}
+	}
+	return cells
+}
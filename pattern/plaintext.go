@@ -0,0 +1,57 @@
+package pattern
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readPlaintext reads the plaintext format of .cells files: a row per line,
+// with O for a live cell and . for a dead one, after comment lines starting
+// with !. Some files use * for live cells too.
+func readPlaintext(r io.Reader) (*Pattern, error) {
+	scanner := bufio.NewScanner(r)
+	p := &Pattern{}
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.HasPrefix(line, "!") {
+			continue
+		}
+		p.Rows = append(p.Rows, strings.Map(func(ch rune) rune {
+			if ch == 'O' || ch == '*' {
+				return 'O'
+			}
+			return '.'
+		}, line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WritePlaintext writes the pattern in the plaintext format of .cells files,
+// leaving out dead cells at the ends of rows. The format has no way to give
+// the rule, so it goes in a comment.
+func (p *Pattern) WritePlaintext(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if p.Rule != "" {
+		fmt.Fprintf(bw, "!Rule: %v\n", p.Rule)
+	}
+	for _, row := range p.Rows {
+		// An empty row is a lone dot, so the file never starts with a blank
+		// line and looks like something else to Read.
+		line := strings.TrimRight(strings.Map(func(ch rune) rune {
+			if ch == 'O' {
+				return 'O'
+			}
+			return '.'
+		}, row), ".")
+		if line == "" {
+			line = "."
+		}
+		fmt.Fprintln(bw, line)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,135 @@
+package pattern
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+)
+
+// pngSignature starts every PNG file.
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+var (
+	pngDead = color.RGBA{0, 0, 0, 255}
+	pngLive = color.RGBA{255, 212, 57, 255}
+)
+
+// WritePNG writes the pattern as a picture with one pixel for each cell, top
+// row first, with the rule and generation in tEXt chunks so ReadPNG can put
+// them back.
+func (p *Pattern) WritePNG(w io.Writer) error {
+	width, height := 1, len(p.Rows)
+	for _, row := range p.Rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	if height == 0 {
+		height = 1
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.SetRGBA(x, y, pngDead)
+		}
+	}
+	for _, c := range p.cells() {
+		img.SetRGBA(c[0], c[1], pngLive)
+	}
+
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		return err
+	}
+
+	// The chunks go straight after IHDR, which is always the first chunk and
+	// 25 bytes long.
+	data := encoded.Bytes()
+	split := len(pngSignature) + 25
+	var text bytes.Buffer
+	if p.Rule != "" {
+		writeTextChunk(&text, "Rule", p.Rule)
+	}
+	if p.Generation != 0 {
+		writeTextChunk(&text, "Generation", strconv.Itoa(p.Generation))
+	}
+	for _, part := range [][]byte{data[:split], text.Bytes(), data[split:]} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTextChunk writes a PNG tEXt chunk holding keyword and value.
+func writeTextChunk(w *bytes.Buffer, keyword, value string) {
+	body := append([]byte("tEXt"+keyword+"\x00"), value...)
+	binary.Write(w, binary.BigEndian, uint32(len(body)-4))
+	w.Write(body)
+	binary.Write(w, binary.BigEndian, crc32.ChecksumIEEE(body))
+}
+
+// ReadPNG reads a picture with one pixel for each cell, as WritePNG writes,
+// taking pixels brighter than half as live cells, and the rule and
+// generation from its tEXt chunks if it has them.
+func ReadPNG(r io.Reader) (*Pattern, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pattern{}
+	for keyword, value := range textChunks(data) {
+		switch keyword {
+		case "Rule":
+			p.Rule = value
+		case "Generation":
+			if p.Generation, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("generation %q: %v", value, err)
+			}
+		}
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := make([]byte, bounds.Dx())
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			row[x-bounds.Min.X] = '.'
+			if gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray); gray.Y >= 128 {
+				row[x-bounds.Min.X] = 'O'
+			}
+		}
+		p.Rows = append(p.Rows, string(row))
+	}
+	return p, nil
+}
+
+// textChunks returns the keywords and values of the tEXt chunks in a PNG
+// file, which png.Decode has already checked.
+func textChunks(data []byte) map[string]string {
+	text := map[string]string{}
+	for at := len(pngSignature); at+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[at:]))
+		kind := string(data[at+4 : at+8])
+		if at+12+length > len(data) {
+			break
+		}
+		body := data[at+8 : at+8+length]
+		if i := bytes.IndexByte(body, 0); kind == "tEXt" && i >= 0 {
+			text[string(body[:i])] = string(body[i+1:])
+		}
+		at += 12 + length
+	}
+	return text
+}
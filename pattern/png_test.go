@@ -0,0 +1,29 @@
+package pattern
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// TestPNGRoundTrip checks that a pattern written as PNG reads back the same,
+// rule and generation included, through Read as well as ReadPNG.
+func TestPNGRoundTrip(t *testing.T) {
+	p := &Pattern{
+		Rows:       []string{".O.....", "..O....", "OOO...O"},
+		Rule:       "B36/S23",
+		Generation: 1234,
+	}
+	var buf bytes.Buffer
+	if err := p.WritePNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("read back %+v, want %+v", got, p)
+	}
+}
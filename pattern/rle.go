@@ -0,0 +1,149 @@
+package pattern
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// ReadRLE reads a pattern in run-length encoded format. Any state other than
+// b or . counts as live.
+func ReadRLE(r io.Reader) (*Pattern, error) {
+	scanner := bufio.NewScanner(r)
+	p := &Pattern{}
+	var row strings.Builder
+	count := 0
+	done := false
+
+	for scanner.Scan() && !done {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "x") {
+			// The rule comes last and runs to the end of the line, since
+			// Larger than Life rules have commas of their own.
+			fields := strings.Split(line, ",")
+			for i, field := range fields {
+				if kv := strings.SplitN(field, "=", 2); len(kv) == 2 && strings.TrimSpace(kv[0]) == "rule" {
+					p.Rule = strings.TrimSpace(strings.Join(append([]string{kv[1]}, fields[i+1:]...), ","))
+					break
+				}
+			}
+			continue
+		}
+
+		for _, ch := range line {
+			run := count
+			if run == 0 {
+				run = 1
+			}
+
+			switch {
+			case ch >= '0' && ch <= '9':
+				count = count*10 + int(ch-'0')
+				continue
+			case ch == '!':
+				done = true
+			case ch == '$':
+				p.Rows = append(p.Rows, row.String())
+				row.Reset()
+				for i := 1; i < run; i++ {
+					p.Rows = append(p.Rows, "")
+				}
+			case ch == 'b' || ch == '.':
+				row.WriteString(strings.Repeat(".", run))
+			case unicode.IsLetter(ch):
+				row.WriteString(strings.Repeat("O", run))
+			case unicode.IsSpace(ch):
+				continue
+			default:
+				return nil, fmt.Errorf("unexpected %q in RLE", ch)
+			}
+			count = 0
+			if done {
+				break
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !done {
+		return nil, fmt.Errorf("RLE ends without !")
+	}
+	p.Rows = append(p.Rows, row.String())
+	return p, nil
+}
+
+// rleLineLength is the longest line WriteRLE writes, as the format suggests.
+const rleLineLength = 70
+
+// WriteRLE writes the pattern in run-length encoded format, with the rule in
+// the header if it has one.
+func (p *Pattern) WriteRLE(w io.Writer) error {
+	width := 0
+	for _, row := range p.Rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	header := fmt.Sprintf("x = %d, y = %d", width, len(p.Rows))
+	if p.Rule != "" {
+		header += ", rule = " + p.Rule
+	}
+
+	// Row ends are held back until the next live cell, so runs of empty rows
+	// become one token and trailing ones are dropped.
+	var tokens []string
+	pendingEnds := 0
+	for i, row := range p.Rows {
+		if i > 0 {
+			pendingEnds++
+		}
+		last := strings.LastIndexByte(row, 'O')
+		if last < 0 {
+			continue
+		}
+		if pendingEnds > 0 {
+			tokens = append(tokens, runToken(pendingEnds, '$'))
+			pendingEnds = 0
+		}
+		for x := 0; x <= last; {
+			live := row[x] == 'O'
+			n := 1
+			for x+n <= last && (row[x+n] == 'O') == live {
+				n++
+			}
+			tag := byte('b')
+			if live {
+				tag = 'o'
+			}
+			tokens = append(tokens, runToken(n, tag))
+			x += n
+		}
+	}
+	tokens = append(tokens, "!")
+
+	var body strings.Builder
+	line := 0
+	for _, t := range tokens {
+		if line+len(t) > rleLineLength {
+			body.WriteString("\n")
+			line = 0
+		}
+		body.WriteString(t)
+		line += len(t)
+	}
+	_, err := fmt.Fprintf(w, "%v\n%v\n", header, body.String())
+	return err
+}
+
+func runToken(n int, tag byte) string {
+	if n == 1 {
+		return string(tag)
+	}
+	return fmt.Sprintf("%d%c", n, tag)
+}
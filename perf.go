@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// perfInterval is how often the performance counter's figures are updated.
+const perfInterval = 500 * time.Millisecond
+
+// perfCounter averages the frame rate, the time spent drawing each frame and
+// the time spent simulating each generation over perfInterval, for the F3
+// counter in the HUD. Its methods must be called holding the simulation lock.
+type perfCounter struct {
+	since time.Time
+
+	frames int
+	render time.Duration
+
+	generations int
+	simulation  time.Duration
+
+	summary string
+}
+
+func newPerfCounter() *perfCounter {
+	return &perfCounter{since: time.Now()}
+}
+
+// step records n generations simulated in d.
+func (p *perfCounter) step(n int, d time.Duration) {
+	p.generations += n
+	p.simulation += d
+}
+
+// frame records a frame drawn in d, bringing the summary up to date once
+// perfInterval has passed.
+func (p *perfCounter) frame(d time.Duration) {
+	p.frames++
+	p.render += d
+
+	elapsed := time.Since(p.since)
+	if elapsed < perfInterval {
+		return
+	}
+
+	simulation := 0.0
+	if p.generations > 0 {
+		simulation = milliseconds(p.simulation) / float64(p.generations)
+	}
+	p.summary = fmt.Sprintf(tr("%.0f fps, %.1f ms/frame (drawing %.1f ms, simulating %.2f ms/generation)"),
+		float64(p.frames)/elapsed.Seconds(), milliseconds(elapsed)/float64(p.frames),
+		milliseconds(p.render)/float64(p.frames), simulation)
+
+	*p = perfCounter{since: time.Now(), summary: p.summary}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
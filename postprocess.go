@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"strings"
+)
+
+// postEffects are the passes the board can be run through once it's drawn, in
+// the order they're applied. Each is post.frag built with its name in capitals
+// defined. Scanlines come before CRT curvature so they bend with the picture.
+var postEffects = []string{"bloom", "scanlines", "crt"}
+
+// parsePostEffects reads a comma-separated list of effects, such as
+// "bloom,crt", as given to --post.
+func parsePostEffects(list string) (map[string]bool, error) {
+	enabled := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, effect := range postEffects {
+			known = known || effect == name
+		}
+		if !known {
+			return nil, fmt.Errorf("no effect %q; try %v", name, strings.Join(postEffects, ", "))
+		}
+		enabled[name] = true
+	}
+	return enabled, nil
+}
+
+// postChain draws the board into an offscreen framebuffer instead of the
+// window when any effects are on, and then runs it through each of them in
+// turn, the last drawing into the window. Text drawn after end isn't
+// affected. The offscreen framebuffer isn't multisampled, so --msaa only
+// smooths what's drawn while no effects are on.
+type postChain struct {
+	// enabled is which of postEffects are on, by name. off overrides it, for
+	// low-power mode.
+	enabled map[string]bool
+	off     bool
+
+	shaders *shaderCache
+
+	// quads and targets hold the window-covering quad and the framebuffers
+	// in each window's GL context, indexed by view, as with cellRenderer.
+	// Framebuffers aren't shared between contexts.
+	quads   []uint32
+	targets []*postTarget
+
+	// drawing is whether begin pointed drawing offscreen, for end to finish.
+	drawing bool
+}
+
+// postTarget is a pair of framebuffers the passes draw back and forth
+// between, the size of a window's framebuffer. The board is drawn into the
+// first, which alone has a depth buffer.
+type postTarget struct {
+	framebuffers  [2]uint32
+	textures      [2]uint32
+	depth         uint32
+	width, height int
+}
+
+// newPostChain makes the chain with its quad in the current context, which
+// should be the main window's.
+func newPostChain(enabled map[string]bool) *postChain {
+	p := &postChain{enabled: enabled, shaders: newShaderCache("post.vert", "post.frag")}
+	p.addView()
+	return p
+}
+
+// addView makes the chain's quad for another window, whose context must be
+// current. Its framebuffers are made the first time an effect is on.
+func (p *postChain) addView() {
+	p.quads = append(p.quads, boardQuad())
+	p.targets = append(p.targets, &postTarget{})
+}
+
+// toggle turns the effect called name on or off.
+func (p *postChain) toggle(name string) {
+	p.enabled[name] = !p.enabled[name]
+}
+
+// active reports whether any effect is on.
+func (p *postChain) active() bool {
+	if p.off {
+		return false
+	}
+	for _, effect := range postEffects {
+		if p.enabled[effect] {
+			return true
+		}
+	}
+	return false
+}
+
+// begin points drawing at w's offscreen framebuffer, made or resized to
+// match w's own, if any effect is on. w's context must be current. A
+// minimized window has no framebuffer to match, so is drawn as it is.
+func (p *postChain) begin(w *glfw.Window) {
+	framebufferWidth, framebufferHeight := w.GetFramebufferSize()
+	p.drawing = p.active() && framebufferWidth > 0 && framebufferHeight > 0
+	if !p.drawing {
+		return
+	}
+	t := p.targets[activeView]
+	if t.width != framebufferWidth || t.height != framebufferHeight {
+		t.resize(framebufferWidth, framebufferHeight)
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.framebuffers[0])
+}
+
+// end runs what was drawn since begin through each effect that's on, the
+// last into the window.
+func (p *postChain) end() {
+	if !p.drawing {
+		return
+	}
+	p.drawing = false
+	var passes []string
+	for _, effect := range postEffects {
+		if p.enabled[effect] {
+			passes = append(passes, effect)
+		}
+	}
+
+	t := p.targets[activeView]
+	gl.BindVertexArray(p.quads[activeView])
+	gl.ActiveTexture(gl.TEXTURE0)
+	for i, effect := range passes {
+		prog := p.shaders.program(strings.ToUpper(effect))
+		gl.UseProgram(prog)
+		gl.Uniform1i(gl.GetUniformLocation(prog, gl.Str("u_frame\x00")), 0)
+		gl.Uniform2f(gl.GetUniformLocation(prog, gl.Str("u_resolution\x00")), float32(t.width), float32(t.height))
+
+		gl.BindTexture(gl.TEXTURE_2D, t.textures[i%2])
+		if i == len(passes)-1 {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+		} else {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, t.framebuffers[(i+1)%2])
+		}
+		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(square)/3))
+	}
+}
+
+// resize makes the target's textures and framebuffers at the given size in
+// pixels. The context they're for must be current.
+func (t *postTarget) resize(width, height int) {
+	if t.textures[0] != 0 {
+		gl.DeleteFramebuffers(2, &t.framebuffers[0])
+		gl.DeleteTextures(2, &t.textures[0])
+		gl.DeleteRenderbuffers(1, &t.depth)
+	}
+	t.width, t.height = width, height
+
+	gl.GenRenderbuffers(1, &t.depth)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, t.depth)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+
+	gl.GenTextures(2, &t.textures[0])
+	gl.GenFramebuffers(2, &t.framebuffers[0])
+	for i, texture := range t.textures {
+		gl.BindTexture(gl.TEXTURE_2D, texture)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+		gl.BindFramebuffer(gl.FRAMEBUFFER, t.framebuffers[i])
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, texture, 0)
+		if i == 0 {
+			gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, t.depth)
+		}
+		if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+			panic(fmt.Sprintf("post-processing framebuffer is incomplete: 0x%x", status))
+		}
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
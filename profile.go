@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+	"os"
+	"path/filepath"
+)
+
+// presentation is how the board is shown and run, apart from the rule.
+type presentation struct {
+	theme   string
+	palette string // gradient or mono, as with --palette
+	filled  bool   // draw live cells filled in rather than outlined
+	tps     float64
+	trails  bool
+}
+
+// profile is how a rule looks best, applied over the settings the game
+// started with whenever the rule is chosen. Fields left out keep those
+// settings.
+type profile struct {
+	Theme   string  `json:"theme"`
+	Palette string  `json:"palette"`
+	Filled  *bool   `json:"filled"`
+	TPS     float64 `json:"tps"`
+	Trails  *bool   `json:"trails"`
+}
+
+// profiles holds each rule's profile by preset name, or for other rules by
+// rulestring as the rule prints itself. Profiles from the user's
+// profiles.json replace these.
+var profiles = map[string]profile{
+	"seeds":            {Theme: "ember", Trails: &yes},
+	"daynight":         {Theme: "crowding", Filled: &yes},
+	"lifewithoutdeath": {Theme: "classic", Filled: &yes, TPS: 30},
+	"briansbrain":      {Theme: "ember", TPS: 15},
+	"starwars":         {Theme: "solarized", TPS: 15},
+}
+
+// yes is there to take the address of in profiles.
+var yes = true
+
+// over returns base with p's settings in place of its own, except the ones
+// whose flags were set, since they were asked for directly.
+func (p profile) over(base presentation, set map[string]bool) presentation {
+	if p.Theme != "" && !set["theme"] {
+		base.theme = p.Theme
+	}
+	if p.Palette != "" && !set["palette"] {
+		base.palette = p.Palette
+	}
+	if p.Filled != nil {
+		base.filled = *p.Filled
+	}
+	if p.TPS != 0 && !set["tps"] {
+		base.tps = p.TPS
+	}
+	if p.Trails != nil && !set["trails"] {
+		base.trails = *p.Trails
+	}
+	return base
+}
+
+// check reports a profile with a theme that doesn't exist, or settings that
+// the flags they stand in for wouldn't take.
+func (p profile) check() error {
+	if p.Theme != "" {
+		if _, err := lookupTheme(p.Theme); err != nil {
+			return err
+		}
+	}
+	if p.Palette != "" && p.Palette != "gradient" && p.Palette != "mono" {
+		return fmt.Errorf("palette %q should be gradient or mono", p.Palette)
+	}
+	if p.TPS < 0 || p.TPS > maxTPS {
+		return fmt.Errorf("tps %v should be above 0 and at most %.0f", p.TPS, maxTPS)
+	}
+	return nil
+}
+
+// profilesPath returns where the user's profiles are kept, next to their
+// themes.
+func profilesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gol", "profiles.json"), nil
+}
+
+// loadUserProfiles replaces the profiles of the rules in profiles.json, if
+// there is one, which maps each rule, by preset name or rulestring, to its
+// profile:
+//
+//	{"highlife": {"theme": "solarized", "tps": 20, "trails": true},
+//	 "B3/S12345": {"palette": "mono", "filled": true}}
+//
+// It must run after loadUserThemes, so profiles can use the user's themes.
+func loadUserProfiles() error {
+	path, err := profilesPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var defined map[string]profile
+	if err := json.Unmarshal(data, &defined); err != nil {
+		return fmt.Errorf("reading %v: %v", path, err)
+	}
+	for name, p := range defined {
+		if err := p.check(); err != nil {
+			return fmt.Errorf("reading %v: %v: %v", path, name, err)
+		}
+		key, err := profileKey(name)
+		if err != nil {
+			return fmt.Errorf("reading %v: %v", path, err)
+		}
+		profiles[key] = p
+	}
+	return nil
+}
+
+// profileKey returns what profiles holds the rule called name under, so
+// "B36/S23" and "highlife" find the same profile.
+func profileKey(name string) (string, error) {
+	if r, err := life.LookupRule(name); err == nil {
+		return presetName(r), nil
+	}
+	r, err := life.ParseLtL(name)
+	if err != nil {
+		return "", fmt.Errorf("%q is neither a rule nor a Larger than Life rule", name)
+	}
+	return r.String(), nil
+}
+
+// activeProfileKey returns what profiles holds the running rule under.
+func activeProfileKey() string {
+	if activeLtL != nil {
+		return activeLtL.String()
+	}
+	return presetName(activeRule)
+}
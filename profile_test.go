@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+// TestProfiles checks that the built-in profiles only use settings that
+// exist, and that flags set on the command line win over them.
+func TestProfiles(t *testing.T) {
+	for name, p := range profiles {
+		if _, err := profileKey(name); err != nil {
+			t.Errorf("%v: %v", name, err)
+		}
+		if err := p.check(); err != nil {
+			t.Errorf("%v: %v", name, err)
+		}
+	}
+
+	base := presentation{theme: "default", palette: "gradient", tps: 10}
+	p := profile{Theme: "ember", Palette: "mono", Filled: &yes, TPS: 30, Trails: &yes}
+	if got, want := p.over(base, nil), (presentation{"ember", "mono", true, 30, true}); got != want {
+		t.Errorf("with no flags set, got %+v, want %+v", got, want)
+	}
+	set := map[string]bool{"theme": true, "tps": true, "trails": true}
+	if got, want := p.over(base, set), (presentation{"default", "mono", true, 10, false}); got != want {
+		t.Errorf("with --theme, --tps and --trails set, got %+v, want %+v", got, want)
+	}
+	if got := (profile{}).over(base, nil); got != base {
+		t.Errorf("an empty profile changed %+v to %+v", base, got)
+	}
+
+	if key, _ := profileKey("B36/S23"); key != "highlife" {
+		t.Errorf("B36/S23 has the profile of %v, want highlife", key)
+	}
+	if _, err := profileKey("B3/S23/X"); err == nil {
+		t.Error("a profile was accepted for a rule that doesn't parse")
+	}
+}
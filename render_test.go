@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var updateRenderGoldens = flag.Bool("update-render-goldens", false, "write testdata/render.golden from this machine's renders")
+
+// renderGoldens holds the hash of each render in TestRenderGoldens, along
+// with the GL renderer they were made with, since drivers rasterize
+// differently. Make them with
+//
+//	LIBGL_ALWAYS_SOFTWARE=1 go test -run TestRenderGoldens -update-render-goldens
+//
+// under a display, or xvfb-run, so they come from Mesa's software renderer.
+const renderGoldens = "testdata/render.golden"
+
+// calls carries work to the main thread, which GLFW needs; TestMain serves it
+// while the tests run on other goroutines.
+var calls = make(chan func())
+
+func TestMain(m *testing.M) {
+	done := make(chan int)
+	go func() { done <- m.Run() }()
+	for {
+		select {
+		case f := <-calls:
+			f()
+		case code := <-done:
+			os.Exit(code)
+		}
+	}
+}
+
+// onMain runs f on the main thread and waits for it.
+func onMain(f func()) {
+	done := make(chan struct{})
+	calls <- func() {
+		defer close(done)
+		f()
+	}
+	<-done
+}
+
+// renderCase is a board drawn one way.
+type renderCase struct {
+	board    string // a key of renderBoards
+	renderer string // instanced or texture
+	look     string // gradient, mono or post
+}
+
+func (c renderCase) String() string {
+	return c.board + "/" + c.renderer + "/" + c.look
+}
+
+// renderBoards are the boards drawn, as rows from the top with O for a live
+// cell and 2 for a cell decaying under Brian's Brain, along with the rule.
+var renderBoards = map[string]struct {
+	rule string
+	rows []string
+}{
+	"glider": {"B3/S23", []string{
+		"................",
+		"..O.............",
+		"...O............",
+		".OOO............",
+		"................",
+		"..........OO....",
+		"..........OO....",
+		"................",
+		"................",
+		"......OOO.......",
+		"................",
+		"................",
+		"................",
+		"................",
+		"................",
+		"................",
+	}},
+	"brain": {"briansbrain", []string{
+		"................",
+		"................",
+		"....O2..........",
+		"....2O..........",
+		"................",
+		"................",
+		"..........O.....",
+		"..........2.....",
+		"..........2.....",
+		"................",
+		"................",
+		"...22OO.........",
+		"................",
+		"................",
+		"................",
+		"................",
+	}},
+}
+
+// TestRenderGoldens draws known boards offscreen, with each renderer and
+// with the palettes and post-processing fixed, reads each frame back from the
+// window after the post chain, and checks its hash against renderGoldens, so
+// that changes to the renderers show up as changed pixels. It's skipped
+// without a display or without goldens for this machine's GL renderer.
+func TestRenderGoldens(t *testing.T) {
+	var cases []renderCase
+	for board := range renderBoards {
+		for _, renderer := range []string{"instanced", "texture"} {
+			for _, look := range []string{"gradient", "mono", "post"} {
+				cases = append(cases, renderCase{board, renderer, look})
+			}
+		}
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].String() < cases[j].String() })
+
+	var glRenderer string
+	var hashes map[string]string
+	var err error
+	onMain(func() { glRenderer, hashes, err = renderAll(cases) })
+	if err != nil {
+		t.Skip("can't render offscreen:", err)
+	}
+
+	if *updateRenderGoldens {
+		if err := writeRenderGoldens(glRenderer, cases, hashes); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	wantRenderer, want, err := readRenderGoldens()
+	if os.IsNotExist(err) {
+		t.Skipf("no %v; make it with -update-render-goldens", renderGoldens)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantRenderer != glRenderer {
+		t.Skipf("the goldens are for %q, and this is %q", wantRenderer, glRenderer)
+	}
+	for _, c := range cases {
+		if hashes[c.String()] != want[c.String()] {
+			t.Errorf("%v hashes to %v, want %v", c, hashes[c.String()], want[c.String()])
+		}
+	}
+}
+
+// TestPackMatchesSnapshot checks, without a display, what the texture
+// renderer uploads and what the instanced renderer draws against the board's
+// snapshot, so the two paths agree on which cells are shown and where.
+func TestPackMatchesSnapshot(t *testing.T) {
+	savedColumns, savedRows, savedRule := columns, rows, activeRule
+	defer func() {
+		columns, rows = savedColumns, savedRows
+		setRule(savedRule)
+	}()
+
+	for name, board := range renderBoards {
+		r, err := life.LookupRule(board.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		setRule(r)
+		cells := renderCells(board.rows)
+		columns, rows = len(cells), len(cells[0])
+		meta := newChannels(cells, channelAge|channelHeat)
+		alive := snapshot(cells)
+
+		tex := &textureRenderer{}
+		tex.pack(cells, meta, func(x, y int) float32 {
+			if x == 0 {
+				return 0.5
+			}
+			return 0
+		}, true)
+		if len(tex.texels) != 4*columns*rows {
+			t.Fatalf("%v: %v texels, want %v", name, len(tex.texels), 4*columns*rows)
+		}
+
+		drawn := map[[2]int]cellInstance{}
+		for x := range cells {
+			for _, c := range cells[x] {
+				if inst, ok := c.instance(); ok {
+					drawn[[2]int{inst.x, inst.y}] = inst
+				}
+			}
+		}
+
+		for x := range cells {
+			for y, c := range cells[x] {
+				i := 4 * (y*columns + x)
+				state, age, heat, neighbors := tex.texels[i], tex.texels[i+1], tex.texels[i+2], tex.texels[i+3]
+				if state != c.state {
+					t.Errorf("%v: texel %v,%v holds state %v, want %v", name, x, y, state, c.state)
+				}
+				if (state == live) != alive[x][y] {
+					t.Errorf("%v: texel %v,%v is alive %v, the snapshot %v", name, x, y, state == live, alive[x][y])
+				}
+				if alive[x][y] && age != 1 {
+					t.Errorf("%v: texel %v,%v has age %v, want 1", name, x, y, age)
+				}
+				if want := uint8(0); x == 0 {
+					if heat != 127 {
+						t.Errorf("%v: texel %v,%v has heat %v, want 127", name, x, y, heat)
+					}
+				} else if heat != want {
+					t.Errorf("%v: texel %v,%v has heat %v, want 0", name, x, y, heat)
+				}
+				if want := c.liveNeighbors(cells); int(neighbors) != want {
+					t.Errorf("%v: texel %v,%v has %v neighbors, want %v", name, x, y, neighbors, want)
+				}
+
+				inst, ok := drawn[[2]int{x, y}]
+				if ok != (c.state != dead) {
+					t.Errorf("%v: cell %v,%v in state %v drawn %v", name, x, y, c.state, ok)
+				}
+				if ok && alive[x][y] && inst.decay != 0 {
+					t.Errorf("%v: live cell %v,%v drawn with decay %v", name, x, y, inst.decay)
+				}
+				if ok && !alive[x][y] && inst.decay <= 0 {
+					t.Errorf("%v: decaying cell %v,%v drawn as if alive", name, x, y)
+				}
+			}
+		}
+	}
+}
+
+// renderAll draws every case in a hidden window and returns the hash of
+// each, by name, along with the GL renderer that drew them. It must run on
+// the main thread.
+func renderAll(cases []renderCase) (glRenderer string, hashes map[string]string, err error) {
+	// GLFW reports some failures, such as there being no display, by
+	// panicking on the next call rather than from Init.
+	defer func() {
+		if r := recover(); r != nil {
+			glRenderer, hashes, err = "", nil, fmt.Errorf("%v", r)
+		}
+	}()
+	if err := glfw.Init(); err != nil {
+		return "", nil, err
+	}
+	defer glfw.Terminate()
+
+	glfw.DefaultWindowHints()
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	glfw.WindowHint(glfw.Resizable, glfw.False)
+	glfw.WindowHint(glfw.ContextVersionMajor, 4)
+	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	window, err := glfw.CreateWindow(128, 128, "render test", nil, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	defer window.Destroy()
+	window.MakeContextCurrent()
+	if err := gl.Init(); err != nil {
+		return "", nil, err
+	}
+	fitViewport(window)
+	glRenderer = gl.GoStr(gl.GetString(gl.RENDERER))
+
+	// The renders depend on these globals, which are put back after.
+	savedColumns, savedRows, savedRule, savedTheme, savedView := columns, rows, activeRule, activeTheme, view
+	defer func() {
+		columns, rows, activeTheme, view = savedColumns, savedRows, savedTheme, savedView
+		setRule(savedRule)
+	}()
+	columns, rows, activeTheme, view = 16, 16, themes[0], camera{Zoom: 1}
+
+	shaders := newShaderCache("cell.vert", "cell.frag")
+	boardShaders := newShaderCache("board.vert", "board.frag")
+	cellsRenderer := newCellRenderer()
+	boardRenderer := newTextureRenderer()
+	gradients := newGradientTexture()
+	effects := newPostChain(map[string]bool{})
+	background := activeTheme.background
+
+	hashes = map[string]string{}
+	for _, c := range cases {
+		board := renderBoards[c.board]
+		r, err := life.LookupRule(board.rule)
+		if err != nil {
+			return "", nil, err
+		}
+		setRule(r)
+		cells := renderCells(board.rows)
+		meta := newChannels(cells, channelAge|channelHeat)
+
+		var features []string
+		if c.look == "mono" {
+			features = append(features, "PALETTE_MONO")
+		}
+		for _, effect := range postEffects {
+			effects.enabled[effect] = c.look == "post"
+		}
+
+		effects.begin(window)
+		gl.ClearColor(background[0], background[1], background[2], 1)
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		if c.renderer == "texture" {
+			prog := boardShaders.program(features...)
+			gl.UseProgram(prog)
+			setUniforms(prog, window, viewOffset, 0, background, gradients)
+			boardRenderer.upload(cells, meta, func(x, y int) float32 { return 0 }, false)
+			boardRenderer.draw(prog)
+		} else {
+			var drawn []cellInstance
+			for x := range cells {
+				for y, cell := range cells[x] {
+					if inst, ok := cell.instance(); ok {
+						inst.age = float32(meta.age[meta.index(x, y)])
+						drawn = append(drawn, inst)
+					}
+				}
+			}
+			prog := shaders.program(features...)
+			gl.UseProgram(prog)
+			setUniforms(prog, window, viewOffset, 0, background, gradients)
+			cellsRenderer.draw(prog, drawn, false)
+		}
+		effects.end()
+
+		img := screenshot(window)
+		hashes[c.String()] = fmt.Sprintf("%x", sha256.Sum256(img.Pix))
+	}
+	return glRenderer, hashes, nil
+}
+
+// renderCells makes a board from rows as in renderBoards.
+func renderCells(rows []string) [][]*cell {
+	cells := make([][]*cell, len(rows[0]))
+	for x := range cells {
+		for y := range rows {
+			c := newCell(x, y)
+			// Rows are given top first, and y runs up.
+			switch rows[len(rows)-1-y][x] {
+			case 'O':
+				c.state = live
+			case '2':
+				c.state = 2
+			}
+			c.stateNext = c.state
+			cells[x] = append(cells[x], c)
+		}
+	}
+	return cells
+}
+
+// readRenderGoldens reads the GL renderer and hashes by name written by
+// writeRenderGoldens.
+func readRenderGoldens() (glRenderer string, hashes map[string]string, err error) {
+	f, err := os.Open(renderGoldens)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	hashes = map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "renderer ") {
+			glRenderer = strings.TrimPrefix(line, "renderer ")
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) == 2 {
+			hashes[fields[0]] = fields[1]
+		}
+	}
+	return glRenderer, hashes, scanner.Err()
+}
+
+func writeRenderGoldens(glRenderer string, cases []renderCase, hashes map[string]string) error {
+	if err := os.MkdirAll("testdata", 0o755); err != nil {
+		return err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "renderer %v\n", glRenderer)
+	for _, c := range cases {
+		fmt.Fprintf(&sb, "%v %v\n", c, hashes[c.String()])
+	}
+	return os.WriteFile(renderGoldens, []byte(sb.String()), 0o644)
+}
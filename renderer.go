@@ -0,0 +1,108 @@
+package main
+
+import "github.com/go-gl/gl/v4.1-core/gl"
+
+// cellInstance is a cell to draw: where it is, and how it looks.
+type cellInstance struct {
+	x, y  int
+	decay float32 // how far a decaying cell has decayed, from 0 to 1
+	fade  float32 // how bright the cell is partway through a generation
+	diff  int     // 1 for a birth and 2 for a death in diff mode, else 0
+	age   float32 // generations the cell has been alive in a row
+	heat  float32 // how bright a dead cell's trail is, or 0 for a cell that isn't a trail
+
+	neighbors float32 // live neighbors, for themes that grade cells by density
+}
+
+// plain returns the cell at x, y drawn plainly.
+func plain(x, y int) cellInstance {
+	return cellInstance{x: x, y: y, fade: 1}
+}
+
+// floatsPerInstance is how many floats a cellInstance takes in the instance
+// buffer: x, y, decay, fade, diff, age, heat and neighbors.
+const floatsPerInstance = 8
+
+// cellRenderer draws any number of cells with one instanced draw call. Every
+// cell is the same square, moved into place and styled by its instance's
+// attributes, so drawing costs the same few calls however big the board is.
+type cellRenderer struct {
+	square    uint32
+	instances uint32
+
+	// vaos holds the vertex array over both buffers in each window's GL
+	// context, indexed by view. Buffers are shared between the contexts, but
+	// vertex arrays can't be.
+	vaos []uint32
+
+	// data is the instance buffer's contents, kept to reuse its memory.
+	data []float32
+}
+
+// newCellRenderer makes a renderer with its vertex array in the current
+// context, which should be the main window's.
+func newCellRenderer() *cellRenderer {
+	r := &cellRenderer{}
+	gl.GenBuffers(1, &r.square)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.square)
+	gl.BufferData(gl.ARRAY_BUFFER, NUM_BYTES_IN_32_BIT*len(square), gl.Ptr(square), gl.STATIC_DRAW)
+	gl.GenBuffers(1, &r.instances)
+
+	r.addView()
+	return r
+}
+
+// addView makes the renderer's vertex array for another window, whose context
+// must be current.
+func (r *cellRenderer) addView() {
+	var vao uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.square)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 0, nil)
+
+	stride := int32(floatsPerInstance * NUM_BYTES_IN_32_BIT)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.instances)
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, stride, nil)
+	gl.VertexAttribDivisor(1, 1)
+	gl.EnableVertexAttribArray(2)
+	gl.VertexAttribPointer(2, 4, gl.FLOAT, false, stride, gl.PtrOffset(2*NUM_BYTES_IN_32_BIT))
+	gl.VertexAttribDivisor(2, 1)
+	gl.EnableVertexAttribArray(3)
+	gl.VertexAttribPointer(3, 1, gl.FLOAT, false, stride, gl.PtrOffset(6*NUM_BYTES_IN_32_BIT))
+	gl.VertexAttribDivisor(3, 1)
+	gl.EnableVertexAttribArray(4)
+	gl.VertexAttribPointer(4, 1, gl.FLOAT, false, stride, gl.PtrOffset(7*NUM_BYTES_IN_32_BIT))
+	gl.VertexAttribDivisor(4, 1)
+
+	r.vaos = append(r.vaos, vao)
+}
+
+// draw draws cells with prog, which must be in use, as outlines or filled in.
+func (r *cellRenderer) draw(prog uint32, cells []cellInstance, filled bool) {
+	if len(cells) == 0 {
+		return
+	}
+
+	r.data = r.data[:0]
+	for _, c := range cells {
+		r.data = append(r.data, float32(c.x), float32(c.y), c.decay, c.fade, float32(c.diff), c.age, c.heat, c.neighbors)
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.instances)
+	gl.BufferData(gl.ARRAY_BUFFER, NUM_BYTES_IN_32_BIT*len(r.data), gl.Ptr(r.data), gl.STREAM_DRAW)
+
+	instancedLocation := gl.GetUniformLocation(prog, gl.Str("u_instanced\x00"))
+	gl.Uniform1i(instancedLocation, 1)
+	gl.Uniform2f(gl.GetUniformLocation(prog, gl.Str("u_cells\x00")), float32(columns), float32(rows))
+
+	mode := uint32(gl.LINE_LOOP)
+	if filled {
+		mode = gl.TRIANGLES
+	}
+	gl.BindVertexArray(r.vaos[activeView])
+	gl.DrawArraysInstanced(mode, 0, int32(len(square)/3), int32(len(cells)))
+	gl.Uniform1i(instancedLocation, 0)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"github.com/jake-shasteen/golang-gl-conway-life/pattern"
+	"io"
+	"os"
+	"strings"
+)
+
+// encodeRLE writes the live cells of alive, indexed [x][y] like the board, in
+// Golly's run-length encoded format, cropped to their bounding box. The rule
+// goes in the header.
+func encodeRLE(w io.Writer, alive [][]bool, ruleString string) error {
+	p := &pattern.Pattern{Rule: ruleString}
+	minX, minY, maxX, maxY, ok := boundingBox(alive)
+	if ok {
+		// RLE runs top to bottom, and the board's y axis points up.
+		for y := maxY; y >= minY; y-- {
+			var row strings.Builder
+			for x := minX; x <= maxX; x++ {
+				if alive[x][y] {
+					row.WriteByte('O')
+				} else {
+					row.WriteByte('.')
+				}
+			}
+			p.Rows = append(p.Rows, row.String())
+		}
+	}
+	return p.WriteRLE(w)
+}
+
+// boundingBox returns the smallest rectangle holding every live cell, or
+// false if there are none.
+func boundingBox(alive [][]bool) (minX, minY, maxX, maxY int, ok bool) {
+	for x := range alive {
+		for y, a := range alive[x] {
+			if !a {
+				continue
+			}
+			if !ok {
+				minX, minY, maxX, maxY, ok = x, y, x, y, true
+				continue
+			}
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+			if y < minY {
+				minY = y
+			}
+			if y > maxY {
+				maxY = y
+			}
+		}
+	}
+	return
+}
+
+// saveBoard writes the live cells of the board to path, as macrocell if path
+// ends in .mc and as RLE otherwise.
+func saveBoard(path string, cells [][]*cell) error {
+	if strings.HasSuffix(strings.ToLower(path), ".mc") {
+		return saveMacrocell(path, cells)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := encodeRLE(f, snapshot(cells), ruleName()); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// ruleName returns the rulestring of the rule the board is running.
+func ruleName() string {
+	if activeLtL != nil {
+		return activeLtL.String()
+	}
+	return activeRule.String()
+}
@@ -0,0 +1,56 @@
+package main
+
+import "github.com/jake-shasteen/golang-gl-conway-life/life"
+
+// rule is a birth/survival rule, shared with the life package.
+type rule = life.Rule
+
+// activeRule is the rule the board runs, and lifeTable is it compiled once up
+// front so each cell is a table lookup instead of a chain of comparisons.
+// Change them with setRule while holding the simulation lock.
+var (
+	activeRule = life.Conway
+	lifeTable  = life.Conway.Compile()
+)
+
+func setRule(r rule) {
+	activeRule = r
+	lifeTable = r.Compile()
+	activeLtL = nil
+}
+
+// chooseRule points the --rule or --ltl flag at name, saved from an earlier
+// run as either a B/S rule or preset or a Larger than Life rule, and clears
+// --ltl for a B/S rule so it takes effect. It returns false if name is
+// neither.
+func chooseRule(name string, ruleString, ltl *string) bool {
+	if _, err := life.LookupRule(name); err == nil {
+		*ruleString, *ltl = name, ""
+		return true
+	}
+	if _, err := life.ParseLtL(name); err == nil {
+		*ltl = name
+		return true
+	}
+	return false
+}
+
+// nextPreset returns the preset after r, or the first preset if r isn't one.
+func nextPreset(r rule) rule {
+	for i, p := range life.Presets {
+		if p.Rule.String() == r.String() {
+			return life.Presets[(i+1)%len(life.Presets)].Rule
+		}
+	}
+	return life.Presets[0].Rule
+}
+
+// presetName returns the name of the preset r matches, or its rulestring.
+func presetName(r rule) string {
+	for _, p := range life.Presets {
+		if p.Rule.String() == r.String() {
+			return p.Name
+		}
+	}
+	return r.String()
+}
@@ -0,0 +1,53 @@
+package main
+
+import "time"
+
+// maxCatchUp caps how many generations the scheduler hands out at once after
+// falling behind, so a stall doesn't turn into a burst of steps.
+const maxCatchUp = 5
+
+// maxTPS is the fastest rate the scheduler runs at, which keeps the interval
+// between generations from rounding down to nothing.
+const maxTPS = 1e6
+
+// scheduler runs generations at a fixed rate however often it is polled. Time
+// that passes between polls is banked and paid out as whole generations.
+type scheduler struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newScheduler(tps float64) *scheduler {
+	s := &scheduler{next: time.Now()}
+	s.setRate(tps)
+	return s
+}
+
+// setRate changes how many generations run per second from now on, up to
+// maxTPS.
+func (s *scheduler) setRate(tps float64) {
+	if tps > maxTPS {
+		tps = maxTPS
+	}
+	s.interval = time.Duration(float64(time.Second) / tps)
+}
+
+// due returns how many generations should be stepped by now and moves the
+// schedule past them.
+func (s *scheduler) due(now time.Time) int {
+	if now.Before(s.next) {
+		return 0
+	}
+	n := int(now.Sub(s.next)/s.interval) + 1
+	if n > maxCatchUp {
+		s.next = now.Add(s.interval)
+		return maxCatchUp
+	}
+	s.next = s.next.Add(time.Duration(n) * s.interval)
+	return n
+}
+
+// until returns how long it is until the next generation is due.
+func (s *scheduler) until(now time.Time) time.Duration {
+	return s.next.Sub(now)
+}
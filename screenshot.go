@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"image"
+)
+
+// screenshot reads back what has been drawn to the window so far this frame.
+// Call it before SwapBuffers, with the window's context current.
+func screenshot(w *glfw.Window) *image.RGBA {
+	width, height := w.GetFramebufferSize()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	gl.ReadBuffer(gl.BACK)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+
+	// GL's rows start at the bottom; the image's start at the top.
+	stride := img.Stride
+	row := make([]uint8, stride)
+	for y := 0; y < height/2; y++ {
+		top, bottom := img.Pix[y*stride:(y+1)*stride], img.Pix[(height-1-y)*stride:(height-y)*stride]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+
+	// The framebuffer's alpha isn't meant to be seen.
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
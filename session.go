@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// session is the state of the game when it was last closed, saved in the
+// user's config directory so the next launch can pick up where it left off.
+type session struct {
+	Rule       string  `json:"rule"`
+	Boundary   string  `json:"boundary"`
+	Columns    int     `json:"columns"`
+	Rows       int     `json:"rows"`
+	Generation int     `json:"generation"`
+	Camera     camera  `json:"camera"`
+	TPS        float64 `json:"tps"`
+
+	// Cells holds every cell's state as returned by board.Save, so decaying
+	// cells under Generations rules come back as they were.
+	Cells []uint8 `json:"cells"`
+}
+
+// sessionPath returns where the session is kept, such as
+// ~/.config/gol/session.json on Linux.
+func sessionPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gol", "session.json"), nil
+}
+
+// loadSession reads the saved session, or returns nil if there isn't one.
+func loadSession() (*session, error) {
+	path, err := sessionPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	if s.Columns <= 0 || s.Rows <= 0 || len(s.Cells) != s.Columns*s.Rows || s.TPS <= 0 || s.Camera.Zoom <= 0 {
+		return nil, fmt.Errorf("reading %v: the session is damaged", path)
+	}
+	return &s, nil
+}
+
+// saveSession writes the board as it is now, and the settings it runs under,
+// as the session to restore next time.
+func saveSession(b *board, generation int, tps float64) error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+
+	s := session{
+		Rule:       ruleName(),
+		Boundary:   boundary.String(),
+		Columns:    len(b.cells),
+		Rows:       len(b.cells[0]),
+		Generation: generation,
+		Camera:     view,
+		TPS:        tps,
+		Cells:      b.Save(nil),
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
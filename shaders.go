@@ -0,0 +1,245 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// shaderPollInterval is how often the shader directory is checked for edits.
+const shaderPollInterval = 500 * time.Millisecond
+
+// shaderFiles holds the shaders built into the binary, used unless
+// --shader-dir points somewhere else.
+//
+//go:embed shaders
+var shaderFiles embed.FS
+
+// shaderDir is where shaders are read from instead of the built-in copies, so
+// they can be edited while the program runs. Empty means the built-in ones.
+var shaderDir string
+
+// shaderSource returns the shader called name, such as "cell.frag", with each
+// `#include "file"` line replaced by that file, ready for compileShader.
+func shaderSource(name string) (string, error) {
+	return shaderSourceIn(shaderDir, name)
+}
+
+// shaderSourceIn is shaderSource reading from dir, or from the built-in
+// shaders if dir is empty.
+func shaderSourceIn(dir, name string) (string, error) {
+	var data []byte
+	var err error
+	if dir != "" {
+		data, err = os.ReadFile(filepath.Join(dir, name))
+	} else {
+		data, err = shaderFiles.ReadFile("shaders/" + name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var source strings.Builder
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		var included string
+		if _, err := fmt.Sscanf(strings.TrimSpace(line), "#include %q", &included); err != nil {
+			source.WriteString(line)
+			continue
+		}
+		text, err := shaderSourceIn(dir, included)
+		if err != nil {
+			return "", fmt.Errorf("%v: %v", name, err)
+		}
+		source.WriteString(strings.TrimSuffix(text, "\x00"))
+	}
+	return source.String() + "\x00", nil
+}
+
+// shaderCache builds a pair of shaders for each combination of features
+// they're asked for, as #defines placed after the #version line, and keeps
+// every linked program so switching features back and forth is free.
+//
+// The features are:
+//
+//	TRAILS        cells fade, for interpolation between generations
+//	PALETTE_MONO  cells are drawn in flat grey instead of the gradient
+//	HIGH_CONTRAST cells are drawn in white, overriding the palette
+type shaderCache struct {
+	vertexFile, fragmentFile string
+
+	programs map[string]uint32
+}
+
+func newShaderCache(vertexFile, fragmentFile string) *shaderCache {
+	return &shaderCache{
+		vertexFile:   vertexFile,
+		fragmentFile: fragmentFile,
+		programs:     make(map[string]uint32),
+	}
+}
+
+// program returns the linked program with features defined, compiling it the
+// first time those features are asked for. The order of features doesn't
+// matter.
+func (s *shaderCache) program(features ...string) uint32 {
+	features = append([]string(nil), features...)
+	sort.Strings(features)
+	key := strings.Join(features, " ")
+	if prog, ok := s.programs[key]; ok {
+		return prog
+	}
+
+	// A shader being edited in --shader-dir may not compile, so that falls
+	// back to the built-in copy. The built-in shaders failing is a bug.
+	prog, err := s.build(shaderDir, features)
+	if err != nil && shaderDir != "" {
+		log.Println("Using the built-in shaders:", err)
+		prog, err = s.build("", features)
+	}
+	if err != nil {
+		panic(err)
+	}
+	s.programs[key] = prog
+	return prog
+}
+
+// reload rebuilds every program from the shader files as they are now. A
+// program that no longer compiles or links is logged and kept as it was, so a
+// typo mid-edit doesn't take the window down.
+func (s *shaderCache) reload() {
+	for key, old := range s.programs {
+		prog, err := s.build(shaderDir, strings.Fields(key))
+		if err != nil {
+			log.Println("Keeping the old shaders:", err)
+			continue
+		}
+		gl.DeleteProgram(old)
+		s.programs[key] = prog
+	}
+}
+
+// build reads the pair of shaders from dir, as shaderSourceIn does, and
+// compiles and links them with features defined.
+func (s *shaderCache) build(dir string, features []string) (uint32, error) {
+	vertexSource, err := shaderSourceIn(dir, s.vertexFile)
+	if err != nil {
+		return 0, err
+	}
+	fragmentSource, err := shaderSourceIn(dir, s.fragmentFile)
+	if err != nil {
+		return 0, err
+	}
+
+	vertexShader, err := compileShader(withDefines(vertexSource, features), gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %v", s.vertexFile, err)
+	}
+	fragmentShader, err := compileShader(withDefines(fragmentSource, features), gl.FRAGMENT_SHADER)
+	if err != nil {
+		gl.DeleteShader(vertexShader)
+		return 0, fmt.Errorf("%v: %v", s.fragmentFile, err)
+	}
+
+	prog := gl.CreateProgram()
+
+	gl.AttachShader(prog, vertexShader)
+	gl.AttachShader(prog, fragmentShader)
+	gl.LinkProgram(prog)
+
+	// The program keeps what it needs, so the shaders can go once linked.
+	gl.DeleteShader(vertexShader)
+	gl.DeleteShader(fragmentShader)
+
+	var status int32
+	gl.GetProgramiv(prog, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(prog, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(prog, logLength, nil, gl.Str(log))
+
+		gl.DeleteProgram(prog)
+		return 0, fmt.Errorf("failed to link %v and %v: %v", s.vertexFile, s.fragmentFile, log)
+	}
+	return prog, nil
+}
+
+// withDefines inserts a #define for each feature into source just after its
+// #version line, which GLSL requires to come first.
+func withDefines(source string, features []string) string {
+	var defines strings.Builder
+	for _, f := range features {
+		fmt.Fprintf(&defines, "#define %v\n", f)
+	}
+
+	i := strings.Index(source, "#version")
+	i += strings.Index(source[i:], "\n") + 1
+	return source[:i] + defines.String() + source[i:]
+}
+
+// shaderWatcher notices when files in shaderDir are saved, by polling their
+// modification times. A nil *shaderWatcher never sees a change, so callers
+// needn't check whether --shader-dir was given.
+type shaderWatcher struct {
+	checked  time.Time
+	modified map[string]time.Time
+}
+
+// newShaderWatcher watches shaderDir, or returns nil if shaders are built in.
+func newShaderWatcher() *shaderWatcher {
+	if shaderDir == "" {
+		return nil
+	}
+	w := &shaderWatcher{}
+	w.modified = w.scan()
+	return w
+}
+
+// changed reports whether any shader has been saved, added or removed since it
+// last returned true. It's cheap to call every frame: it only looks at the
+// directory every shaderPollInterval.
+func (w *shaderWatcher) changed() bool {
+	if w == nil || time.Since(w.checked) < shaderPollInterval {
+		return false
+	}
+	modified := w.scan()
+	if len(modified) == len(w.modified) {
+		same := true
+		for name, t := range modified {
+			if !w.modified[name].Equal(t) {
+				same = false
+			}
+		}
+		if same {
+			return false
+		}
+	}
+	w.modified = modified
+	return true
+}
+
+// scan returns when each file in shaderDir was last modified.
+func (w *shaderWatcher) scan() map[string]time.Time {
+	w.checked = time.Now()
+	modified := make(map[string]time.Time)
+	entries, err := os.ReadDir(shaderDir)
+	if err != nil {
+		log.Println("Watching shaders:", err)
+		return w.modified
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.Type().IsRegular() {
+			continue
+		}
+		modified[entry.Name()] = info.ModTime()
+	}
+	return modified
+}
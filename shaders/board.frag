@@ -0,0 +1,46 @@
+#version 410
+
+// The board is a four-channel texture holding each cell's state, age, trail
+// heat and live neighbors, one texel per cell. Unless cells are drawn filled
+// in, as in high-contrast mode, only each cell's outline and the diagonal the
+// instanced renderer's line loop draws are kept, so the two renderers look
+// the same.
+
+uniform sampler2D u_board;
+uniform vec2 u_cells;
+uniform int u_states;
+
+in vec2 v_board;
+
+#include "color.glsl"
+
+out vec4 FragColor;
+
+void main() {
+    vec2 at = v_board * u_cells;
+    ivec2 texel = clamp(ivec2(at), ivec2(0), ivec2(u_cells) - 1);
+    vec4 cell = texelFetch(u_board, texel, 0) * vec4(255.0, 255.0, 1.0, 255.0);
+    int state = int(cell.r + 0.5);
+    bool trail = state == 0 && cell.b > 0.0;
+    if ((state == 0 && !trail) || state >= u_states) {
+        discard;
+    }
+
+#if !defined(HIGH_CONTRAST) && !defined(FILLED)
+    vec2 f = fract(at);
+    vec2 w = fwidth(at);
+    bool edge = f.x < w.x || f.x > 1.0 - w.x || f.y < w.y || f.y > 1.0 - w.y;
+    bool diagonal = abs(f.x + f.y - 1.0) < w.x + w.y;
+    if (!edge && !diagonal) {
+        discard;
+    }
+#endif
+
+    if (trail) {
+        FragColor = vec4(trailColor(cell.b), 1.0);
+        return;
+    }
+
+    float decay = float(state - 1) / float(max(u_states - 1, 1));
+    FragColor = vec4(cellColor(decay, cell.g, cell.a), 1.0);
+}
@@ -0,0 +1,19 @@
+#version 410
+
+uniform float u_time;
+uniform vec3 u_camera;
+uniform vec2 u_view;
+uniform vec2 u_projection;
+
+// vp is a corner of the quad covering the whole board, in board
+// coordinates; v_board runs from 0 to 1 across it.
+layout(location = 0) in vec3 vp;
+
+out vec2 v_board;
+
+void main() {
+    float pct = 0.9 + abs(sin(u_time / 2.0) / 10.0);
+    v_board = (vp.xy + 1.0) / 2.0;
+    vec2 p = (vp.xy - u_camera.xy) * u_camera.z;
+    gl_Position = vec4((p.x * u_view.x + u_view.y * pct) * u_projection.x, p.y * u_projection.y, vp.z, pct);
+}
@@ -0,0 +1,48 @@
+#version 410
+
+uniform vec4 u_overlay;
+
+flat in float v_decay;
+flat in float v_fade;
+flat in float v_diff;
+flat in float v_age;
+flat in float v_heat;
+flat in float v_neighbors;
+
+vec3 birthColor = vec3(0.180,0.800,0.251);
+vec3 deathColor = vec3(0.863,0.196,0.184);
+
+#include "color.glsl"
+
+out vec4 FragColor;
+
+void main() {
+    // Overlays (pending edits, the hovered cell) draw in a flat color.
+    if (u_overlay.a > 0.0) {
+        FragColor = u_overlay;
+        return;
+    }
+
+    // In diff mode only the cells that differ from the reference are drawn.
+    if (v_diff == 1.0) {
+        FragColor = vec4(birthColor, 1.0);
+        return;
+    }
+    if (v_diff == 2.0) {
+        FragColor = vec4(deathColor, 1.0);
+        return;
+    }
+
+    if (v_heat > 0.0) {
+        FragColor = vec4(trailColor(v_heat), 1.0);
+        return;
+    }
+
+    vec3 color = cellColor(v_decay, v_age, v_neighbors);
+
+#ifdef TRAILS
+    FragColor = vec4(color * v_fade,1.0);
+#else
+    FragColor = vec4(color,1.0);
+#endif
+}
@@ -0,0 +1,59 @@
+#version 410
+
+uniform float u_time;
+
+// u_camera is the point of the board at the middle of the window and
+// how far it is zoomed in.
+uniform vec3 u_camera;
+
+// u_view zooms and pans the board horizontally, so a window can show
+// just part of it: x becomes x * u_view.x + u_view.y.
+uniform vec2 u_view;
+
+// u_projection scales the window's x and y so the board isn't stretched
+// to the window's shape and cells stay square.
+uniform vec2 u_projection;
+
+// Cells are drawn instanced: vp is a corner of a square a cell across,
+// a_cell moves it to its cell on a board u_cells in size, a_style holds
+// its decay, fade, diff and age, a_heat its trail's brightness, and
+// a_neighbors how many live neighbors it has.
+// Anything else is drawn with u_instanced off and vp already in board
+// coordinates.
+uniform bool u_instanced;
+uniform vec2 u_cells;
+
+layout(location = 0) in vec3 vp;
+layout(location = 1) in vec2 a_cell;
+layout(location = 2) in vec4 a_style;
+layout(location = 3) in float a_heat;
+layout(location = 4) in float a_neighbors;
+
+flat out float v_decay;
+flat out float v_fade;
+flat out float v_diff;
+flat out float v_age;
+flat out float v_heat;
+flat out float v_neighbors;
+
+void main() {
+    float pct = 0.9 + abs(sin(u_time / 2.0) / 10.0);
+    vec3 pos = vp;
+    v_decay = 0.0;
+    v_fade = 1.0;
+    v_diff = 0.0;
+    v_age = 0.0;
+    v_heat = 0.0;
+    v_neighbors = 0.0;
+    if (u_instanced) {
+        pos.xy = (a_cell + vp.xy + 0.5) / u_cells * 2.0 - 1.0;
+        v_decay = a_style.x;
+        v_fade = a_style.y;
+        v_diff = a_style.z;
+        v_age = a_style.w;
+        v_heat = a_heat;
+        v_neighbors = a_neighbors;
+    }
+    vec2 p = (pos.xy - u_camera.xy) * u_camera.z;
+    gl_Position = vec4((p.x * u_view.x + u_view.y * pct) * u_projection.x, p.y * u_projection.y, pos.z, pct);
+}
@@ -0,0 +1,49 @@
+// Included by the fragment shaders that color cells.
+
+// u_gradient and u_background come from the theme. The gradient is 256
+// colors from newborn cells to those 128 generations old or, with
+// u_byDensity, from cells with no live neighbors to those with 8.
+uniform sampler1D u_gradient;
+uniform bool u_byDensity;
+uniform vec3 u_background;
+
+vec3 decayColor = vec3(0.420,0.106,0.604);
+vec3 emberColor = vec3(0.851,0.325,0.098);
+
+// cellColor is the color of a cell, with decay running from 0 for a live
+// cell to 1 for one about to die, age the number of generations it has
+// been alive in a row, and neighbors how many of its neighbors are live.
+vec3 cellColor(float decay, float age, float neighbors) {
+    vec3 color = vec3(0.0);
+
+#if defined(HIGH_CONTRAST)
+    color = vec3(1.0);
+#elif defined(PALETTE_MONO)
+    color = vec3(0.9);
+#else
+    // Cells move along the gradient as they survive, reaching its end
+    // after 128 generations, so still lifes stand out from the churn
+    // around them. Ages are spaced by their logarithm, as gradient.go
+    // samples them.
+    float pct = clamp(log2(max(age, 1.0)) / 7.0, 0.0, 1.0);
+    if (u_byDensity) {
+        pct = neighbors / 8.0;
+    }
+    // The middles of the first and last texels are the gradient's ends.
+    color = texture(u_gradient, (pct * 255.0 + 0.5) / 256.0).rgb;
+#endif
+
+    // Decaying cells under Generations rules shift towards decayColor and
+    // darken as they age.
+    return mix(color, decayColor, decay) * (1.0 - 0.6 * decay);
+}
+
+// trailColor is the color of a dead cell's trail, fading into the
+// background as its heat runs from 1 down to 0.
+vec3 trailColor(float heat) {
+#if defined(HIGH_CONTRAST) || defined(PALETTE_MONO)
+    return mix(u_background, vec3(0.6), heat);
+#else
+    return mix(u_background, emberColor, heat);
+#endif
+}
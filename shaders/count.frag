@@ -0,0 +1,16 @@
+#version 410
+
+// Each fragment is one cell of the board, as in gpu.frag. Dead cells are
+// discarded, so an occlusion query over the whole board counts the live ones.
+
+uniform sampler2D u_board;
+uniform vec2 u_size;
+
+out vec4 color;
+
+void main() {
+    if (texture(u_board, gl_FragCoord.xy / u_size).r < 0.5) {
+        discard;
+    }
+    color = vec4(1.0);
+}
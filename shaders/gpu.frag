@@ -0,0 +1,31 @@
+#version 410
+
+// The board is a one-channel texture, one texel per cell. Each fragment is
+// one cell of the next generation; u_birth and u_survive have bit n set if a
+// cell with n live neighbors is born or survives. The texture's wrap mode
+// stands in for the boundary mode.
+
+uniform sampler2D u_board;
+uniform vec2 u_size;
+uniform int u_birth;
+uniform int u_survive;
+
+out vec4 next;
+
+bool alive(vec2 at) {
+    return texture(u_board, at / u_size).r > 0.5;
+}
+
+void main() {
+    int n = 0;
+    for (int dx = -1; dx <= 1; dx++) {
+        for (int dy = -1; dy <= 1; dy++) {
+            if ((dx != 0 || dy != 0) && alive(gl_FragCoord.xy + vec2(dx, dy))) {
+                n++;
+            }
+        }
+    }
+
+    int rule = alive(gl_FragCoord.xy) ? u_survive : u_birth;
+    next = vec4(float((rule >> n) & 1), 0.0, 0.0, 1.0);
+}
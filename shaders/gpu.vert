@@ -0,0 +1,6 @@
+#version 410
+
+layout(location = 0) in vec3 vp;
+void main() {
+    gl_Position = vec4(vp, 1.0);
+}
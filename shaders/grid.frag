@@ -0,0 +1,21 @@
+#version 410
+
+// Lines run along the bottom and left of every cell, a pixel wide, and are
+// left out altogether once cells are too small for them to help.
+
+uniform vec2 u_cells;
+uniform vec3 u_background;
+
+in vec2 v_board;
+
+out vec4 FragColor;
+
+void main() {
+    vec2 at = v_board * u_cells;
+    vec2 f = fract(at);
+    vec2 w = fwidth(at);
+    if (max(w.x, w.y) > 0.25 || (f.x >= w.x && f.y >= w.y)) {
+        discard;
+    }
+    FragColor = vec4(mix(u_background, vec3(1.0), 0.2), 1.0);
+}
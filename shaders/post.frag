@@ -0,0 +1,52 @@
+#version 410
+
+// Each post-processing pass is built with one of BLOOM, SCANLINES or CRT
+// defined, and reads the frame as the passes before it left it.
+
+uniform sampler2D u_frame;
+uniform vec2 u_resolution;
+
+in vec2 v_uv;
+
+out vec4 FragColor;
+
+void main() {
+#if defined(BLOOM)
+    // Bright pixels glow: what's brighter than the threshold nearby is
+    // blurred and added on top, spread a little wider on bigger windows.
+    vec3 color = texture(u_frame, v_uv).rgb;
+    vec2 texel = max(u_resolution.y / 480.0, 1.0) / u_resolution;
+    vec3 glow = vec3(0.0);
+    float total = 0.0;
+    for (int x = -4; x <= 4; x++) {
+        for (int y = -4; y <= 4; y++) {
+            float weight = exp(-float(x * x + y * y) / 8.0);
+            vec3 near = texture(u_frame, v_uv + vec2(x, y) * 2.0 * texel).rgb;
+            float brightness = dot(near, vec3(0.299, 0.587, 0.114));
+            glow += near * max(brightness - 0.35, 0.0) * weight;
+            total += weight;
+        }
+    }
+    FragColor = vec4(color + 2.5 * glow / total, 1.0);
+#elif defined(SCANLINES)
+    // Every third row of pixels is dimmed, like the gaps between a tube's
+    // scanlines.
+    vec3 color = texture(u_frame, v_uv).rgb;
+    float row = mod(floor(gl_FragCoord.y), 3.0);
+    FragColor = vec4(color * (row == 0.0 ? 0.55 : 1.0), 1.0);
+#elif defined(CRT)
+    // The frame bulges out towards the middle like the face of a tube,
+    // leaving black past its edges, and darkens towards the corners.
+    vec2 centered = v_uv * 2.0 - 1.0;
+    centered *= 1.0 + 0.04 * dot(centered, centered);
+    vec2 uv = (centered + 1.0) / 2.0;
+    if (uv.x < 0.0 || uv.x > 1.0 || uv.y < 0.0 || uv.y > 1.0) {
+        FragColor = vec4(0.0, 0.0, 0.0, 1.0);
+        return;
+    }
+    float vignette = 1.0 - 0.25 * dot(centered, centered) / 2.0;
+    FragColor = vec4(texture(u_frame, uv).rgb * vignette, 1.0);
+#else
+    FragColor = texture(u_frame, v_uv);
+#endif
+}
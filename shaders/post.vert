@@ -0,0 +1,12 @@
+#version 410
+
+// vp is a corner of a quad covering the whole window; v_uv runs from 0 to 1
+// across it.
+layout(location = 0) in vec3 vp;
+
+out vec2 v_uv;
+
+void main() {
+    v_uv = (vp.xy + 1.0) / 2.0;
+    gl_Position = vec4(vp.xy, 0.0, 1.0);
+}
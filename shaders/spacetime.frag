@@ -0,0 +1,9 @@
+#version 410
+
+in vec3 v_color;
+
+out vec4 FragColor;
+
+void main() {
+    FragColor = vec4(v_color, 1.0);
+}
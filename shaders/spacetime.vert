@@ -0,0 +1,29 @@
+#version 410
+
+// Each generation held is a layer of cubes, the newest on top: vp is a
+// corner of a cube a cell across, a_normal its face's normal, and a_cell
+// moves it to its cell on a board u_cells in size, a_cell.z generations
+// down. u_transform takes it on through the camera to the window.
+uniform mat4 u_transform;
+uniform vec2 u_cells;
+uniform float u_layers;
+uniform vec3 u_young;
+uniform vec3 u_old;
+uniform vec3 u_background;
+
+layout(location = 0) in vec3 vp;
+layout(location = 1) in vec3 a_normal;
+layout(location = 2) in vec3 a_cell;
+
+out vec3 v_color;
+
+void main() {
+    vec3 pos = vp * 0.9 + vec3(a_cell.xy + 0.5 - u_cells / 2.0, -a_cell.z);
+    gl_Position = u_transform * vec4(pos, 1.0);
+
+    // Older layers take on the old color and sink into the background, and
+    // faces are lit from above so the cubes read as solid.
+    float back = a_cell.z / max(u_layers - 1.0, 1.0);
+    float light = 0.45 + 0.55 * max(dot(a_normal, normalize(vec3(0.4, 0.6, 1.0))), 0.0);
+    v_color = mix(mix(u_young, u_old, back) * light, u_background, back * 0.6);
+}
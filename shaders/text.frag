@@ -0,0 +1,17 @@
+#version 410
+
+// Each character is drawn on a box of u_shade, so it can be read over
+// whatever cells are behind it.
+
+uniform sampler2D u_font;
+uniform vec3 u_color;
+uniform vec3 u_shade;
+
+in vec2 v_texel;
+
+out vec4 FragColor;
+
+void main() {
+    bool lit = texelFetch(u_font, ivec2(v_texel), 0).r > 0.5;
+    FragColor = vec4(lit ? u_color : u_shade, 1.0);
+}
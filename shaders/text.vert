@@ -0,0 +1,17 @@
+#version 410
+
+// Text is laid out in screen coordinates from the window's top-left corner,
+// like cursor positions. a_texel is the matching point of the font atlas, in
+// texels from its top-left corner.
+
+uniform vec2 u_window;
+
+layout(location = 0) in vec2 a_position;
+layout(location = 1) in vec2 a_texel;
+
+out vec2 v_texel;
+
+void main() {
+    v_texel = a_texel;
+    gl_Position = vec4(a_position.x / u_window.x * 2.0 - 1.0, 1.0 - a_position.y / u_window.y * 2.0, 0.0, 1.0);
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// showcaseInterval is how long each pattern runs in the startup showcase.
+const showcaseInterval = 20 * time.Second
+
+// showcase is a pattern drawn as rows of text, top row first, with O for a
+// live cell, plus the caption shown while it runs.
+type showcase struct {
+	caption string
+	pattern []string
+}
+
+var showcases = []showcase{
+	{"Gosper glider gun: the first pattern found to grow forever", []string{
+		"........................O...........",
+		"......................O.O...........",
+		"............OO......OO............OO",
+		"...........O...O....OO............OO",
+		"OO........O.....O...OO..............",
+		"OO........O...O.OO....O.O...........",
+		"..........O.....O.......O...........",
+		"...........O...O....................",
+		"............OO......................",
+	}},
+	{"R-pentomino: five cells that take 1103 generations to settle", []string{
+		".OO",
+		"OO.",
+		".O.",
+	}},
+	{"Pulsar: a period 3 oscillator", []string{
+		"..OOO...OOO..",
+		".............",
+		"O....O.O....O",
+		"O....O.O....O",
+		"O....O.O....O",
+		"..OOO...OOO..",
+		".............",
+		"..OOO...OOO..",
+		"O....O.O....O",
+		"O....O.O....O",
+		"O....O.O....O",
+		".............",
+		"..OOO...OOO..",
+	}},
+	{"Acorn: a methuselah that grows for 5206 generations", []string{
+		".O.....",
+		"...O...",
+		"OO..OOO",
+	}},
+	{"Lightweight spaceship: the smallest orthogonal spaceship", []string{
+		".O..O",
+		"O....",
+		"O...O",
+		"OOOO.",
+	}},
+	{"Diehard: vanishes completely after 130 generations", []string{
+		"......O.",
+		"OO......",
+		".O...OOO",
+	}},
+	{"Glider: the smallest spaceship, moving diagonally", []string{
+		".O.",
+		"..O",
+		"OOO",
+	}},
+}
+
+// pickShowcase returns the index of a showcase chosen with rng, other than
+// last unless last is -1, so the same pattern never runs twice in a row.
+func pickShowcase(rng *rand.Rand, last int) int {
+	if last < 0 {
+		return rng.Intn(len(showcases))
+	}
+	next := rng.Intn(len(showcases) - 1)
+	if next >= last {
+		next++
+	}
+	return next
+}
+
+// clear kills every cell on the board.
+func (b *board) clear() {
+	b.engine.reset()
+	for x := range b.cells {
+		for _, c := range b.cells[x] {
+			c.set(false)
+		}
+	}
+}
+
+// stamp brings the live cells of pattern to life, centered on the board.
+// Cells that fall off the board are left out.
+func (b *board) stamp(pattern []string) {
+	patternWidth := 0
+	for _, line := range pattern {
+		if len(line) > patternWidth {
+			patternWidth = len(line)
+		}
+	}
+
+	b.stampAt(pattern, (len(b.cells)-patternWidth)/2, (len(b.cells[0])+len(pattern))/2-1)
+}
+
+// stampAt brings the live cells of pattern to life with its top left corner
+// at left, top. Cells that fall off the board are left out.
+func (b *board) stampAt(pattern []string, left, top int) {
+	for row, line := range pattern {
+		for col, ch := range line {
+			x, y := left+col, top-row
+			if ch != 'O' || x < 0 || x >= len(b.cells) || y < 0 || y >= len(b.cells[x]) {
+				continue
+			}
+			b.cells[x][y].set(true)
+		}
+	}
+}
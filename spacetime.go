@@ -0,0 +1,263 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"math"
+	"time"
+)
+
+const (
+	// spacetimeFOV is the camera's vertical field of view, in radians.
+	spacetimeFOV = math.Pi / 4
+
+	// spacetimeSpin is how fast the camera orbits on its own until it's
+	// dragged, in radians per second.
+	spacetimeSpin = 0.3
+
+	// floatsPerCubeVertex is a cube corner's position and its face's normal.
+	floatsPerCubeVertex = 6
+)
+
+// spacetimeView draws the most recent generations stacked into a column, the
+// newest on top and each one below a generation older, so gliders trace
+// diagonal tubes and oscillators stack into pillars. Every live cell of every
+// generation held is a cube, drawn instanced with depth testing, seen through
+// a camera orbiting the middle of the column, which the drag that would pan
+// the board turns instead and the wheel moves closer. It only draws in the
+// main window.
+type spacetimeView struct {
+	// layers is a ring buffer of the live cells of each generation captured,
+	// as x, y pairs; next is where the next goes.
+	layers      [][]float32
+	next, count int
+
+	cube      uint32
+	instances uint32
+	vao       uint32
+
+	// data is the instance buffer's contents, rebuilt when dirty.
+	data  []float32
+	dirty bool
+
+	// yaw and pitch are the camera's direction from the middle of the
+	// column, and zoom how much closer it is than fits the whole column.
+	yaw, pitch, zoom float32
+	spinning         bool
+	spunAt           time.Time
+}
+
+// newSpacetimeView makes a view holding up to depth generations, with its
+// vertex array in the current context, which should be the main window's.
+func newSpacetimeView(depth int) *spacetimeView {
+	s := &spacetimeView{
+		layers:   make([][]float32, depth),
+		pitch:    0.5,
+		zoom:     1,
+		spinning: true,
+	}
+
+	mesh := cubeMesh()
+	gl.GenBuffers(1, &s.cube)
+	gl.BindBuffer(gl.ARRAY_BUFFER, s.cube)
+	gl.BufferData(gl.ARRAY_BUFFER, NUM_BYTES_IN_32_BIT*len(mesh), gl.Ptr(mesh), gl.STATIC_DRAW)
+	gl.GenBuffers(1, &s.instances)
+
+	gl.GenVertexArrays(1, &s.vao)
+	gl.BindVertexArray(s.vao)
+	stride := int32(floatsPerCubeVertex * NUM_BYTES_IN_32_BIT)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, stride, nil)
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, stride, gl.PtrOffset(3*NUM_BYTES_IN_32_BIT))
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, s.instances)
+	gl.EnableVertexAttribArray(2)
+	gl.VertexAttribPointer(2, 3, gl.FLOAT, false, 0, nil)
+	gl.VertexAttribDivisor(2, 1)
+	return s
+}
+
+// capture records the live cells of the board as its newest layer, dropping
+// the oldest if the view is full.
+func (s *spacetimeView) capture(cells [][]*cell) {
+	layer := s.layers[s.next][:0]
+	for x := range cells {
+		for y, c := range cells[x] {
+			if c.alive() {
+				layer = append(layer, float32(x), float32(y))
+			}
+		}
+	}
+	s.layers[s.next] = layer
+	s.next = (s.next + 1) % len(s.layers)
+	if s.count < len(s.layers) {
+		s.count++
+	}
+	s.dirty = true
+}
+
+// pop forgets the newest layer, for when the board is stepped backwards.
+func (s *spacetimeView) pop() {
+	if s.count == 0 {
+		return
+	}
+	s.next = (s.next - 1 + len(s.layers)) % len(s.layers)
+	s.count--
+	s.dirty = true
+}
+
+// clear forgets every layer.
+func (s *spacetimeView) clear() {
+	s.count = 0
+	s.dirty = true
+}
+
+// orbit turns the camera around the column by the given angles in radians,
+// and stops it spinning on its own.
+func (s *spacetimeView) orbit(yaw, pitch float32) {
+	s.spinning = false
+	s.yaw += yaw
+	s.pitch += pitch
+	if s.pitch > 1.5 {
+		s.pitch = 1.5
+	} else if s.pitch < -1.5 {
+		s.pitch = -1.5
+	}
+}
+
+// draw draws the column into w with prog, which must be in use, in colors
+// running from young on the newest layer to old on the oldest, which fades
+// into background.
+func (s *spacetimeView) draw(prog uint32, w *glfw.Window, young, old, background rgb) {
+	now := time.Now()
+	if s.spinning && !s.spunAt.IsZero() {
+		s.yaw += float32(now.Sub(s.spunAt).Seconds()) * spacetimeSpin
+	}
+	s.spunAt = now
+
+	if s.dirty {
+		s.data = s.data[:0]
+		for back := 0; back < s.count; back++ {
+			layer := s.layers[(s.next-1-back+2*len(s.layers))%len(s.layers)]
+			for i := 0; i < len(layer); i += 2 {
+				s.data = append(s.data, layer[i], layer[i+1], float32(back))
+			}
+		}
+		gl.BindBuffer(gl.ARRAY_BUFFER, s.instances)
+		gl.BufferData(gl.ARRAY_BUFFER, NUM_BYTES_IN_32_BIT*len(s.data), gl.Ptr(s.data), gl.STREAM_DRAW)
+		s.dirty = false
+	}
+	if len(s.data) == 0 {
+		return
+	}
+
+	// The camera backs off far enough to fit a sphere around the whole
+	// column, then closer by zoom.
+	depth := float32(s.count)
+	radius := float32(math.Sqrt(float64(columns*columns+rows*rows)+float64(depth*depth))) / 2
+	distance := radius / float32(math.Sin(spacetimeFOV/2)) / s.zoom
+	target := vec3{0, 0, -(depth - 1) / 2}
+	eye := vec3{
+		target[0] + distance*float32(math.Cos(float64(s.pitch))*math.Cos(float64(s.yaw))),
+		target[1] + distance*float32(math.Cos(float64(s.pitch))*math.Sin(float64(s.yaw))),
+		target[2] + distance*float32(math.Sin(float64(s.pitch))),
+	}
+	aspect := float32(1)
+	if windowWidth, windowHeight := w.GetSize(); windowWidth > 0 && windowHeight > 0 {
+		aspect = float32(windowWidth) / float32(windowHeight)
+	}
+	near := distance / 100
+	transform := perspective(spacetimeFOV, aspect, near, distance+2*radius).mul(lookAt(eye, target, vec3{0, 0, 1}))
+
+	gl.UniformMatrix4fv(gl.GetUniformLocation(prog, gl.Str("u_transform\x00")), 1, false, &transform[0])
+	gl.Uniform2f(gl.GetUniformLocation(prog, gl.Str("u_cells\x00")), float32(columns), float32(rows))
+	gl.Uniform1f(gl.GetUniformLocation(prog, gl.Str("u_layers\x00")), depth)
+	gl.Uniform3fv(gl.GetUniformLocation(prog, gl.Str("u_young\x00")), 1, &young[0])
+	gl.Uniform3fv(gl.GetUniformLocation(prog, gl.Str("u_old\x00")), 1, &old[0])
+	gl.Uniform3fv(gl.GetUniformLocation(prog, gl.Str("u_background\x00")), 1, &background[0])
+
+	gl.Enable(gl.DEPTH_TEST)
+	gl.BindVertexArray(s.vao)
+	gl.DrawArraysInstanced(gl.TRIANGLES, 0, 36, int32(len(s.data)/3))
+	gl.Disable(gl.DEPTH_TEST)
+}
+
+// cubeMesh returns the triangles of a cube a unit across, centered on the
+// origin, each vertex its position followed by its face's normal.
+func cubeMesh() []float32 {
+	corners := [4][2]float32{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+	var mesh []float32
+	for axis := 0; axis < 3; axis++ {
+		u, v := (axis+1)%3, (axis+2)%3
+		for _, sign := range []float32{-1, 1} {
+			for _, i := range []int{0, 1, 2, 0, 2, 3} {
+				var p, n vec3
+				p[axis], p[u], p[v] = sign/2, corners[i][0]/2, corners[i][1]/2
+				n[axis] = sign
+				mesh = append(mesh, p[0], p[1], p[2], n[0], n[1], n[2])
+			}
+		}
+	}
+	return mesh
+}
+
+// vec3 is a point or direction in the space the column is drawn in, where z
+// is up and runs back through the generations.
+type vec3 [3]float32
+
+func (a vec3) sub(b vec3) vec3 { return vec3{a[0] - b[0], a[1] - b[1], a[2] - b[2]} }
+
+func (a vec3) dot(b vec3) float32 { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+
+func (a vec3) cross(b vec3) vec3 {
+	return vec3{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func (a vec3) normalize() vec3 {
+	length := float32(math.Sqrt(float64(a.dot(a))))
+	return vec3{a[0] / length, a[1] / length, a[2] / length}
+}
+
+// mat4 is a 4x4 matrix in column-major order, the way GLSL takes it.
+type mat4 [16]float32
+
+// mul returns a times b, which transforms by b and then a.
+func (a mat4) mul(b mat4) mat4 {
+	var m mat4
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			for k := 0; k < 4; k++ {
+				m[col*4+row] += a[k*4+row] * b[col*4+k]
+			}
+		}
+	}
+	return m
+}
+
+// perspective returns the projection of a camera with a vertical field of
+// view of fovy radians, onto a window aspect times as wide as it is tall,
+// showing what lies between near and far.
+func perspective(fovy, aspect, near, far float32) mat4 {
+	f := float32(1 / math.Tan(float64(fovy)/2))
+	return mat4{
+		f / aspect, 0, 0, 0,
+		0, f, 0, 0,
+		0, 0, (far + near) / (near - far), -1,
+		0, 0, 2 * far * near / (near - far), 0,
+	}
+}
+
+// lookAt returns the transform into the space of a camera at eye looking
+// towards target, with up pointing up the window.
+func lookAt(eye, target, up vec3) mat4 {
+	f := target.sub(eye).normalize()
+	s := f.cross(up).normalize()
+	u := s.cross(f)
+	return mat4{
+		s[0], u[0], -f[0], 0,
+		s[1], u[1], -f[1], 0,
+		s[2], u[2], -f[2], 0,
+		-s.dot(eye), -u.dot(eye), f.dot(eye), 1,
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+// point is a cell on the unbounded plane.
+type point struct {
+	x, y int
+}
+
+// sparseEngine runs the board as part of an unbounded plane, keeping only the
+// set of live cells, so a pattern can grow as far as it likes. The board is a
+// window onto the plane that follows the pattern around: once the middle of
+// the live cells strays a quarter of the board from the middle of the
+// window, the window is moved to put it back in the middle.
+//
+// Live cells that have left the window live on in the plane through steps
+// and edits, but the board is all that history, bookmarks and sessions keep,
+// so going back to one of those loses them, along with the window's place on
+// the plane.
+//
+// The boundary mode doesn't apply. It handles two-state B/S rules without B0,
+// and falls back to the naive engine otherwise.
+type sparseEngine struct {
+	live map[point]bool
+
+	// origin is where cells[0][0] is on the plane.
+	origin point
+}
+
+func newSparseEngine() *sparseEngine {
+	return &sparseEngine{live: make(map[point]bool)}
+}
+
+// reset drops the plane, so the board becomes all there is, with the window
+// back at the origin.
+func (e *sparseEngine) reset() {
+	e.live = make(map[point]bool)
+	e.origin = point{}
+}
+
+func (e *sparseEngine) advance(cells [][]*cell, k int) {
+	birth, survive := ruleMasks(activeRule)
+	if activeLtL != nil || activeRule.StateCount() > 2 || birth&1 != 0 {
+		naiveEngine{}.advance(cells, k)
+		return
+	}
+
+	// The board may have been edited since the last advance, so within the
+	// window it is the truth.
+	for x := range cells {
+		for y, c := range cells[x] {
+			p := point{e.origin.x + x, e.origin.y + y}
+			if c.alive() {
+				e.live[p] = true
+			} else {
+				delete(e.live, p)
+			}
+		}
+	}
+
+	for i := 0; i < 1<<k; i++ {
+		e.step(birth, survive)
+	}
+
+	e.follow(len(cells), len(cells[0]))
+	for x := range cells {
+		for y, c := range cells[x] {
+			c.set(e.live[point{e.origin.x + x, e.origin.y + y}])
+		}
+	}
+}
+
+func (e *sparseEngine) step(birth, survive uint16) {
+	neighbors := make(map[point]int, len(e.live)*4)
+	for p := range e.live {
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				if dx != 0 || dy != 0 {
+					neighbors[point{p.x + dx, p.y + dy}]++
+				}
+			}
+		}
+	}
+
+	next := make(map[point]bool, len(e.live))
+	for p, n := range neighbors {
+		rule := birth
+		if e.live[p] {
+			rule = survive
+		}
+		if rule&(1<<uint(n)) != 0 {
+			next[p] = true
+		}
+	}
+
+	// Live cells without any live neighbors aren't in neighbors at all.
+	if survive&1 != 0 {
+		for p := range e.live {
+			if _, ok := neighbors[p]; !ok {
+				next[p] = true
+			}
+		}
+	}
+
+	e.live = next
+}
+
+// follow moves the window if the pattern has wandered off its middle.
+func (e *sparseEngine) follow(columns, rows int) {
+	if len(e.live) == 0 {
+		return
+	}
+
+	first := true
+	var lo, hi point
+	for p := range e.live {
+		if first {
+			lo, hi, first = p, p, false
+			continue
+		}
+		if p.x < lo.x {
+			lo.x = p.x
+		}
+		if p.x > hi.x {
+			hi.x = p.x
+		}
+		if p.y < lo.y {
+			lo.y = p.y
+		}
+		if p.y > hi.y {
+			hi.y = p.y
+		}
+	}
+
+	middle := point{(lo.x + hi.x) / 2, (lo.y + hi.y) / 2}
+	dx := middle.x - (e.origin.x + columns/2)
+	dy := middle.y - (e.origin.y + rows/2)
+	if abs(dx) > columns/4 || abs(dy) > rows/4 {
+		e.origin = point{middle.x - columns/2, middle.y - rows/2}
+	}
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
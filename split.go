@@ -0,0 +1,65 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// activeView is the window whose GL context is current: 0 for the main
+// window and 1 for the right half in split mode. Drawing binds the vertex
+// arrays belonging to it.
+var activeView int
+
+// viewScale and viewOffset are the main window's horizontal zoom and pan,
+// as passed to the u_view uniform. Split mode zooms in on the left half.
+var viewScale, viewOffset float32 = 1, 0
+
+// openSplitWindow opens a second window showing the right half of the board,
+// while the main window shows the left half, so a large board can span two
+// monitors. Its context shares buffers and programs with the main window's,
+// but it needs its own vertex arrays for the cells and boundary markers, and
+// its own framebuffers for post-processing.
+//
+// The second window's context is current when openSplitWindow returns.
+func openSplitWindow(primary *glfw.Window, renderer *cellRenderer, board *textureRenderer, grid *gridOverlay, effects *postChain) (*glfw.Window, map[boundaryMode]boundaryMarker) {
+	window, err := glfw.CreateWindow(width, height, tr(title)+" ("+tr("right half")+")", nil, primary)
+	if err != nil {
+		panic(err)
+	}
+
+	// Put the window on the next monitor if there is one, or else beside the
+	// main window.
+	if monitors := glfw.GetMonitors(); len(monitors) > 1 {
+		x, y := monitors[1].GetPos()
+		window.SetPos(x, y)
+	} else {
+		x, y := primary.GetPos()
+		window.SetPos(x+width, y)
+	}
+
+	multisample := gl.IsEnabled(gl.MULTISAMPLE)
+	window.MakeContextCurrent()
+	if multisample {
+		gl.Enable(gl.MULTISAMPLE)
+	}
+
+	// Only the main window waits for vblank, or every frame would wait twice.
+	glfw.SwapInterval(0)
+	fitViewport(window)
+
+	// Its viewport follows its own framebuffer, which needs its own context
+	// current while the main window's is current everywhere else.
+	window.SetFramebufferSizeCallback(func(w *glfw.Window, framebufferWidth, framebufferHeight int) {
+		w.MakeContextCurrent()
+		gl.Viewport(0, 0, int32(framebufferWidth), int32(framebufferHeight))
+		primary.MakeContextCurrent()
+	})
+
+	renderer.addView()
+	board.addView()
+	grid.addView()
+	effects.addView()
+
+	viewScale, viewOffset = 2, 1
+	return window, makeBoundaryMarkers()
+}
@@ -0,0 +1,65 @@
+package main
+
+import "github.com/jake-shasteen/golang-gl-conway-life/life"
+
+// tab is one board of the workspace, with its own rule, camera, history and
+// bookmarks. The tab being shown lives in the game's own variables, and is
+// only gathered into a tab when it's set aside for another. Every tab has the
+// window's board size.
+type tab struct {
+	state    []uint8
+	initial  [][]bool
+	watch    watcher
+	paused   bool
+	rule     rule
+	ltl      *life.LtLRule
+	boundary boundaryMode
+	view     camera
+	past     *history
+	marks    *bookmarks
+}
+
+// workspace holds the open tabs, switched between like documents in an
+// editor. The entry for the tab being shown is out of date until it's set
+// aside.
+type workspace struct {
+	tabs    []*tab
+	current int
+}
+
+// newWorkspace returns a workspace with just the tab being shown.
+func newWorkspace() *workspace {
+	return &workspace{tabs: []*tab{nil}}
+}
+
+// move returns the tab by places after the current one, going round from
+// the last to the first and back.
+func (w *workspace) move(by int) int {
+	n := len(w.tabs)
+	return ((w.current+by)%n + n) % n
+}
+
+// close drops the current tab and returns the one to show in its place, or
+// false if it's the only tab.
+func (w *workspace) close() (*tab, bool) {
+	if len(w.tabs) == 1 {
+		return nil, false
+	}
+	w.tabs = append(w.tabs[:w.current], w.tabs[w.current+1:]...)
+	if w.current == len(w.tabs) {
+		w.current--
+	}
+	return w.tabs[w.current], true
+}
+
+// randomState returns the states of a random board of the current size,
+// made from seed, in the order board.Save uses, for a new tab.
+func randomState(seed int64) []uint8 {
+	state := make([]uint8, columns*rows)
+	for i, alive := range life.Fill(seed, threshold, columns*rows) {
+		if alive {
+			state[i] = live
+		}
+	}
+	return state
+}
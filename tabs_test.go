@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+// TestWorkspace checks that switching goes round the tabs, and that closing
+// one shows the tab after it, or before it if it was the last.
+func TestWorkspace(t *testing.T) {
+	a, b, c := &tab{}, &tab{}, &tab{}
+	w := &workspace{tabs: []*tab{a, b, c}}
+	if got := w.move(-1); got != 2 {
+		t.Errorf("before the first tab comes %v, want 2", got)
+	}
+	if got := w.move(4); got != 1 {
+		t.Errorf("4 after the first tab comes %v, want 1", got)
+	}
+
+	w.current = 1
+	if next, ok := w.close(); !ok || next != c || w.current != 1 {
+		t.Errorf("closing the middle tab showed %p at %v, want %p at 1", next, w.current, c)
+	}
+	if next, ok := w.close(); !ok || next != a || w.current != 0 {
+		t.Errorf("closing the last tab showed %p at %v, want %p at 0", next, w.current, a)
+	}
+	if _, ok := w.close(); ok {
+		t.Error("the only tab was closed")
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// floatsPerTextVertex is how many floats each vertex of text takes: its
+// position and the texel of the atlas it shows.
+const floatsPerTextVertex = 4
+
+// textRenderer draws lines of text with the bitmap font in font.go, each
+// character a quad showing its glyph in the font atlas. It only draws in the
+// main window.
+type textRenderer struct {
+	atlas  uint32
+	buffer uint32
+	vao    uint32
+
+	// data is the vertex buffer's contents, kept to reuse its memory.
+	data []float32
+}
+
+// newTextRenderer uploads the font atlas and makes the renderer's vertex
+// array in the current context, which should be the main window's.
+func newTextRenderer() *textRenderer {
+	t := &textRenderer{}
+
+	texels, width, height := fontAtlas()
+	gl.GenTextures(1, &t.atlas)
+	gl.BindTexture(gl.TEXTURE_2D, t.atlas)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R8, int32(width), int32(height), 0, gl.RED, gl.UNSIGNED_BYTE, gl.Ptr(texels))
+
+	gl.GenBuffers(1, &t.buffer)
+	gl.GenVertexArrays(1, &t.vao)
+	gl.BindVertexArray(t.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, t.buffer)
+	stride := int32(floatsPerTextVertex * NUM_BYTES_IN_32_BIT)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, stride, nil)
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, stride, gl.PtrOffset(2*NUM_BYTES_IN_32_BIT))
+	return t
+}
+
+// draw draws lines of text with prog, which must be in use, with the top-left
+// corner of the first at x, y in screen coordinates and each pixel of the font
+// scale screen coordinates across.
+func (t *textRenderer) draw(prog uint32, lines []string, x, y, scale float32, color, shade rgb) {
+	t.data = t.data[:0]
+	for row, line := range lines {
+		top := y + float32(row*glyphHeight)*scale
+		col := 0
+		for _, r := range line {
+			left := x + float32(col*glyphWidth)*scale
+			right, bottom := left+glyphWidth*scale, top+glyphHeight*scale
+			u := float32(glyphIndex(r) * glyphWidth)
+			t.data = append(t.data,
+				left, top, u, 0,
+				left, bottom, u, glyphHeight,
+				right, bottom, u+glyphWidth, glyphHeight,
+				left, top, u, 0,
+				right, bottom, u+glyphWidth, glyphHeight,
+				right, top, u+glyphWidth, 0,
+			)
+			col++
+		}
+	}
+	if len(t.data) == 0 {
+		return
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, t.buffer)
+	gl.BufferData(gl.ARRAY_BUFFER, NUM_BYTES_IN_32_BIT*len(t.data), gl.Ptr(t.data), gl.STREAM_DRAW)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, t.atlas)
+	gl.Uniform1i(gl.GetUniformLocation(prog, gl.Str("u_font\x00")), 0)
+	gl.Uniform2f(gl.GetUniformLocation(prog, gl.Str("u_window\x00")), float32(width), float32(height))
+	gl.Uniform3fv(gl.GetUniformLocation(prog, gl.Str("u_color\x00")), 1, &color[0])
+	gl.Uniform3fv(gl.GetUniformLocation(prog, gl.Str("u_shade\x00")), 1, &shade[0])
+
+	gl.BindVertexArray(t.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(t.data)/floatsPerTextVertex))
+}
+
+// textScale returns how many screen coordinates across each pixel of the font
+// is drawn in w: two physical pixels, or more on HiDPI displays, whether or
+// not the platform already counts screen coordinates in scaled units.
+func textScale(w *glfw.Window) float32 {
+	windowWidth, _ := w.GetSize()
+	framebufferWidth, _ := w.GetFramebufferSize()
+	contentScale, _ := w.GetContentScale()
+	if windowWidth == 0 || framebufferWidth == 0 || contentScale < 1 {
+		return 2
+	}
+	return 2 * contentScale * float32(windowWidth) / float32(framebufferWidth)
+}
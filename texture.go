@@ -0,0 +1,108 @@
+package main
+
+import "github.com/go-gl/gl/v4.1-core/gl"
+
+// textureRenderer draws the board as one quad whose fragment shader looks up
+// each cell in a texture of cell states, ages, trails and neighbor counts,
+// uploaded whole every frame.
+// Unlike cellRenderer, what it costs doesn't depend on how many cells are
+// alive. A nil *textureRenderer does nothing, so callers needn't check whether
+// it's in use.
+type textureRenderer struct {
+	texture uint32
+
+	// quads holds the vertex array of the quad in each window's GL context,
+	// indexed by view, as with cellRenderer.
+	quads []uint32
+
+	columns, rows int
+	texels        []uint8
+}
+
+// newTextureRenderer makes a renderer with its quad in the current context,
+// which should be the main window's.
+func newTextureRenderer() *textureRenderer {
+	r := &textureRenderer{}
+	gl.GenTextures(1, &r.texture)
+	gl.BindTexture(gl.TEXTURE_2D, r.texture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+	r.addView()
+	return r
+}
+
+// addView makes the renderer's quad for another window, whose context must be
+// current.
+func (r *textureRenderer) addView() {
+	if r == nil {
+		return
+	}
+	r.quads = append(r.quads, boardQuad())
+}
+
+// upload copies the state, age, trail heat and, if density is set, the live
+// neighbors of every cell into the texture, resizing it if the board has
+// changed size. Ages past 255 are stored as 255.
+func (r *textureRenderer) upload(cells [][]*cell, meta *channels, trail func(x, y int) float32, density bool) {
+	if r == nil {
+		return
+	}
+	gl.BindTexture(gl.TEXTURE_2D, r.texture)
+	if r.columns != len(cells) || r.rows != len(cells[0]) {
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(len(cells)), int32(len(cells[0])), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	}
+	r.pack(cells, meta, trail, density)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int32(r.columns), int32(r.rows), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(r.texels))
+}
+
+// pack fills texels with four bytes for each cell, row by row from the
+// bottom: its state, its age, its trail heat scaled to 0-255, and its live
+// neighbors if density is set, or 0.
+func (r *textureRenderer) pack(cells [][]*cell, meta *channels, trail func(x, y int) float32, density bool) {
+	if r.columns != len(cells) || r.rows != len(cells[0]) {
+		r.columns, r.rows = len(cells), len(cells[0])
+		r.texels = make([]uint8, 4*r.columns*r.rows)
+	}
+	for x := range cells {
+		for y, c := range cells[x] {
+			age := meta.age[meta.index(x, y)]
+			if age > 255 {
+				age = 255
+			}
+			neighbors := 0
+			if density {
+				neighbors = c.liveNeighbors(cells)
+			}
+			i := 4 * (y*r.columns + x)
+			r.texels[i], r.texels[i+1], r.texels[i+2], r.texels[i+3] = c.state, uint8(age), uint8(trail(x, y)*255), uint8(neighbors)
+		}
+	}
+}
+
+// boardQuad makes a vertex array in the current context for a quad covering
+// the whole board, as board.vert expects.
+func boardQuad() uint32 {
+	quad := make([]float32, len(square))
+	for i, v := range square {
+		quad[i] = v * 2
+	}
+	return makeVao(quad)
+}
+
+// draw draws the board as last uploaded with prog, which must be in use.
+func (r *textureRenderer) draw(prog uint32) {
+	if r == nil {
+		return
+	}
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, r.texture)
+	gl.Uniform1i(gl.GetUniformLocation(prog, gl.Str("u_board\x00")), 0)
+	gl.Uniform2f(gl.GetUniformLocation(prog, gl.Str("u_cells\x00")), float32(r.columns), float32(r.rows))
+	gl.Uniform1i(gl.GetUniformLocation(prog, gl.Str("u_states\x00")), int32(activeRule.StateCount()))
+
+	gl.BindVertexArray(r.quads[activeView])
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(square)/3))
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// rgb is an RGB color with each component from 0 to 1, the way the
+// shaders take it.
+type rgb [3]float32
+
+// parseHex reads a color written as #rrggbb.
+func parseHex(s string) (rgb, error) {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err != nil || len(s) != 7 {
+		return rgb{}, fmt.Errorf("color %q should look like #ffcc00", s)
+	}
+	return rgb{float32(r) / 255, float32(g) / 255, float32(b) / 255}, nil
+}
+
+// theme is the colors the board is drawn in: live cells colored by gradient,
+// on background. The palette's mono setting and high-contrast mode override
+// the cell colors.
+type theme struct {
+	name       string
+	gradient   gradient
+	background rgb
+}
+
+// themes can be picked by name with --theme or from the command palette.
+// Themes from the user's themes.json are added to the end.
+var themes = []theme{
+	{"default", twoTone(rgb{1.000, 0.833, 0.224}, rgb{0.149, 0.141, 0.912}), rgb{0, 0, 0}},
+	{"classic", twoTone(rgb{0.400, 1.000, 0.400}, rgb{0.000, 0.600, 0.150}), rgb{0, 0, 0}},
+	{"solarized", twoTone(rgb{0.710, 0.537, 0.000}, rgb{0.149, 0.545, 0.824}), rgb{0.000, 0.169, 0.212}},
+	{"grayscale", twoTone(rgb{1.000, 1.000, 1.000}, rgb{0.400, 0.400, 0.400}), rgb{0, 0, 0}},
+	{"ember", gradient{stops: []gradientStop{
+		{1, rgb{1.000, 1.000, 0.850}},
+		{4, rgb{1.000, 0.750, 0.100}},
+		{16, rgb{0.900, 0.250, 0.050}},
+		{128, rgb{0.300, 0.020, 0.050}},
+	}}, rgb{0, 0, 0}},
+	{"crowding", gradient{density: true, stops: []gradientStop{
+		{0, rgb{0.200, 0.400, 1.000}},
+		{2, rgb{0.200, 0.900, 0.500}},
+		{3, rgb{1.000, 0.900, 0.200}},
+		{8, rgb{1.000, 0.100, 0.100}},
+	}}, rgb{0, 0, 0}},
+}
+
+// gradient maps how old a live cell is, or how crowded, to its color. Colors
+// are blended between stops, which must be in order, and past the first and
+// last stops the color holds.
+type gradient struct {
+	// density grades cells by how many live neighbors they have, from 0 to
+	// 8, rather than by how many generations they have been alive, from 1
+	// to 128.
+	density bool
+	stops   []gradientStop
+}
+
+type gradientStop struct {
+	at    float64
+	color rgb
+}
+
+// twoTone is a gradient from young for newborn cells to old for those that
+// have survived 128 generations.
+func twoTone(young, old rgb) gradient {
+	return gradient{stops: []gradientStop{{1, young}, {128, old}}}
+}
+
+// ends returns the colors at either end of the gradient.
+func (g gradient) ends() (rgb, rgb) {
+	return g.stops[0].color, g.stops[len(g.stops)-1].color
+}
+
+// position maps a stop to where it falls along the gradient, from 0 to 1.
+// Ages are spaced by their logarithm, so the first few generations, where
+// most of the churn is, get as much of the gradient as the last hundred.
+func (g gradient) position(at float64) float64 {
+	if g.density {
+		return at / 8
+	}
+	return math.Log2(at) / 7
+}
+
+// colorAt returns the color at t along the gradient, from 0 to 1.
+func (g gradient) colorAt(t float64) rgb {
+	first, last := g.ends()
+	if t <= g.position(g.stops[0].at) {
+		return first
+	}
+	for i := 1; i < len(g.stops); i++ {
+		from, to := g.position(g.stops[i-1].at), g.position(g.stops[i].at)
+		if t > to {
+			continue
+		}
+		f := float32((t - from) / (to - from))
+		var c rgb
+		for j := range c {
+			c[j] = g.stops[i-1].color[j]*(1-f) + g.stops[i].color[j]*f
+		}
+		return c
+	}
+	return last
+}
+
+// check reports a gradient without stops, or with stops out of order or out
+// of range.
+func (g gradient) check() error {
+	if len(g.stops) == 0 {
+		return errors.New("a gradient needs at least one stop")
+	}
+	low, high := 1.0, 128.0
+	if g.density {
+		low, high = 0, 8
+	}
+	for i, s := range g.stops {
+		if s.at < low || s.at > high {
+			return fmt.Errorf("stop at %v isn't between %v and %v", s.at, low, high)
+		}
+		if i > 0 && s.at <= g.stops[i-1].at {
+			return fmt.Errorf("stop at %v comes after %v", s.at, g.stops[i-1].at)
+		}
+	}
+	return nil
+}
+
+// activeTheme is the theme the board is drawn in. Only the main thread uses
+// it, so it needs no lock.
+var activeTheme = themes[0]
+
+// lookupTheme returns the theme called name.
+func lookupTheme(name string) (theme, error) {
+	var names []string
+	for _, t := range themes {
+		if strings.EqualFold(t.name, name) {
+			return t, nil
+		}
+		names = append(names, t.name)
+	}
+	return theme{}, fmt.Errorf("no theme %q; try %v", name, strings.Join(names, ", "))
+}
+
+// themesPath returns where user-defined themes are kept, such as
+// ~/.config/gol/themes.json on Linux.
+func themesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gol", "themes.json"), nil
+}
+
+// loadUserThemes adds the themes in themes.json, if there is one, to themes.
+// The file maps each theme's name to its colors, either young and old, or
+// the stops of a gradient by age in generations or, with "by": "density", by
+// live neighbors:
+//
+//	{"amber": {"young": "#ffb000", "old": "#a05000", "background": "#1a1000"},
+//	 "fire": {"background": "#000000", "stops": [
+//		{"at": 1, "color": "#ffffff"}, {"at": 8, "color": "#ffb000"},
+//		{"at": 128, "color": "#600000"}]}}
+//
+// A user theme with the name of a built-in one replaces it.
+func loadUserThemes() error {
+	path, err := themesPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var defined map[string]struct {
+		Young      string `json:"young"`
+		Old        string `json:"old"`
+		Background string `json:"background"`
+		By         string `json:"by"`
+		Stops      []struct {
+			At    float64 `json:"at"`
+			Color string  `json:"color"`
+		} `json:"stops"`
+	}
+	if err := json.Unmarshal(data, &defined); err != nil {
+		return fmt.Errorf("reading %v: %v", path, err)
+	}
+	var names []string
+	for name := range defined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		d := defined[name]
+		t := theme{name: name}
+		if t.background, err = parseHex(d.Background); err != nil {
+			return fmt.Errorf("reading %v: theme %q: %v", path, name, err)
+		}
+		switch d.By {
+		case "", "age":
+		case "density":
+			t.gradient.density = true
+		default:
+			return fmt.Errorf("reading %v: theme %q: by %q should be age or density", path, name, d.By)
+		}
+
+		if len(d.Stops) == 0 && !t.gradient.density {
+			var young, old rgb
+			if young, err = parseHex(d.Young); err == nil {
+				old, err = parseHex(d.Old)
+			}
+			if err != nil {
+				return fmt.Errorf("reading %v: theme %q: %v", path, name, err)
+			}
+			t.gradient = twoTone(young, old)
+		}
+		for _, s := range d.Stops {
+			stop := gradientStop{at: s.At}
+			if stop.color, err = parseHex(s.Color); err != nil {
+				return fmt.Errorf("reading %v: theme %q: %v", path, name, err)
+			}
+			t.gradient.stops = append(t.gradient.stops, stop)
+		}
+		if err := t.gradient.check(); err != nil {
+			return fmt.Errorf("reading %v: theme %q: %v", path, name, err)
+		}
+
+		replaced := false
+		for i := range themes {
+			if strings.EqualFold(themes[i].name, name) {
+				themes[i], replaced = t, true
+			}
+		}
+		if !replaced {
+			themes = append(themes, t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestGradient checks that a two-tone gradient blends as the shaders did
+// before gradients, and that stops are reached where they're placed.
+func TestGradient(t *testing.T) {
+	young, old := rgb{1, 0.5, 0}, rgb{0, 0.5, 1}
+	two := twoTone(young, old)
+	for _, age := range []float64{1, 2, 11, 64, 128} {
+		pct := float32(math.Log2(age) / 7)
+		got := two.colorAt(two.position(age))
+		for i := range got {
+			if want := young[i]*(1-pct) + old[i]*pct; math.Abs(float64(got[i]-want)) > 1e-5 {
+				t.Errorf("age %v: %v, want %v", age, got, want)
+				break
+			}
+		}
+	}
+
+	for _, g := range []gradient{themes[4].gradient, themes[5].gradient} {
+		if err := g.check(); err != nil {
+			t.Errorf("%v: %v", g, err)
+		}
+		for _, s := range g.stops {
+			if got := g.colorAt(g.position(s.at)); got != s.color {
+				t.Errorf("stop at %v: %v, want %v", s.at, got, s.color)
+			}
+		}
+		first, last := g.ends()
+		if texels := g.texels(); texels[0] != uint8(first[0]*255+0.5) || texels[len(texels)-3] != uint8(last[0]*255+0.5) {
+			t.Errorf("the texels run from %v to %v, want %v to %v", texels[:3], texels[len(texels)-3:], first, last)
+		}
+	}
+
+	for _, g := range []gradient{
+		{},
+		{stops: []gradientStop{{0, young}}},
+		{stops: []gradientStop{{8, young}, {4, old}}},
+		{density: true, stops: []gradientStop{{9, young}}},
+	} {
+		if g.check() == nil {
+			t.Errorf("%v was accepted", g)
+		}
+	}
+}
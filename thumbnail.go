@@ -0,0 +1,83 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+var (
+	thumbnailBackground = color.RGBA{0, 0, 0, 255}
+	thumbnailLive       = color.RGBA{255, 212, 57, 255}
+)
+
+// renderThumbnail draws alive, indexed [x][y] like the board, into an image of
+// the given size without touching OpenGL. Each pixel is shaded by the share
+// of the cells under it that are alive, so small thumbnails of big boards
+// still show where the activity is. The board's y axis points up, so rows are
+// flipped on the way into the image.
+func renderThumbnail(alive [][]bool, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if len(alive) == 0 || len(alive[0]) == 0 {
+		return img
+	}
+	columns, rows := len(alive), len(alive[0])
+
+	for px := 0; px < width; px++ {
+		x0, x1 := span(px, width, columns)
+		for py := 0; py < height; py++ {
+			y0, y1 := span(height-1-py, height, rows)
+
+			live, total := 0, 0
+			for x := x0; x < x1; x++ {
+				for y := y0; y < y1; y++ {
+					if alive[x][y] {
+						live++
+					}
+					total++
+				}
+			}
+
+			img.SetRGBA(px, py, blend(thumbnailBackground, thumbnailLive, float64(live)/float64(total)))
+		}
+	}
+
+	return img
+}
+
+// span returns the range of cells covered by pixel i of n when the pixels
+// are laid over size cells. Every pixel covers at least one cell.
+func span(i, n, size int) (int, int) {
+	start := i * size / n
+	end := (i + 1) * size / n
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
+
+func blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*t)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 255}
+}
+
+// Thumbnail renders the board to an image of the given size.
+func (b *board) Thumbnail(width, height int) image.Image {
+	return renderThumbnail(snapshot(b.cells), width, height)
+}
+
+// savePNG writes img to path as a PNG.
+func savePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+	"github.com/jake-shasteen/golang-gl-conway-life/pattern"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// tournamentThumbnailSize is the width and height of the pictures of each
+// round's final board saved with --thumbnails.
+const tournamentThumbnailSize = 128
+
+// strategy seeds one half of the board for a side in a tournament. x0 and x1
+// are the columns of its half, x0 <= x < x1.
+type strategy func(cells [][]*cell, x0, x1 int, rng *rand.Rand)
+
+// strategies are the built-in seeding strategies. A strategy can also be
+// "exec:path", an external program that prints a pattern in any format
+// pattern.Read understands.
+var strategies = map[string]strategy{
+	"random": soup(0.3),
+	"sparse": soup(0.1),
+	"dense":  soup(0.5),
+	"clusters": func(cells [][]*cell, x0, x1 int, rng *rand.Rand) {
+		for i := 0; i < 8; i++ {
+			cx, cy := x0+rng.Intn(x1-x0), rng.Intn(len(cells[0]))
+			for x := cx - 2; x <= cx+2; x++ {
+				for y := cy - 2; y <= cy+2; y++ {
+					if x >= x0 && x < x1 && y >= 0 && y < len(cells[0]) && rng.Float64() < 0.5 {
+						cells[x][y].set(true)
+					}
+				}
+			}
+		}
+	},
+}
+
+// soup fills the half at random with the given density.
+func soup(density float64) strategy {
+	return func(cells [][]*cell, x0, x1 int, rng *rand.Rand) {
+		for x := x0; x < x1; x++ {
+			for _, c := range cells[x] {
+				c.set(rng.Float64() < density)
+			}
+		}
+	}
+}
+
+// execStrategy runs path, which should print a pattern to stamp in the middle
+// of the half. GOL_SIDE, GOL_WIDTH, GOL_HEIGHT and GOL_SEED tell it
+// what it is playing for.
+func execStrategy(path, side string) strategy {
+	return func(cells [][]*cell, x0, x1 int, rng *rand.Rand) {
+		cmd := exec.Command(path)
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(),
+			"GOL_SIDE="+side,
+			fmt.Sprintf("GOL_WIDTH=%d", x1-x0),
+			fmt.Sprintf("GOL_HEIGHT=%d", len(cells[0])),
+			fmt.Sprintf("GOL_SEED=%d", rng.Int63()),
+		)
+		out, err := cmd.Output()
+		if err != nil {
+			log.Fatalf("running %v: %v", path, err)
+		}
+		p, err := pattern.Read(bytes.NewReader(out))
+		if err != nil {
+			log.Fatalf("reading pattern from %v: %v", path, err)
+		}
+		(&board{cells: cells[x0:x1]}).stamp(p.Rows)
+	}
+}
+
+func lookupStrategy(name, side string) (strategy, error) {
+	if strings.HasPrefix(name, "exec:") {
+		return execStrategy(strings.TrimPrefix(name, "exec:"), side), nil
+	}
+	if s, ok := strategies[name]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q, want random, sparse, dense, clusters or exec:path", name)
+}
+
+// tournament pits two seeding strategies against each other over many
+// rounds, without opening a window, and reports how often each wins. Each
+// side seeds its half of the board, and every cell born takes the side of
+// most of its parents. Whoever holds more live cells at the end wins the
+// round. The sides swap halves every round so neither has the better one.
+func tournament(args []string, w io.Writer) {
+	fs := flag.NewFlagSet("tournament", flag.ExitOnError)
+	a := fs.String("a", "random", "first strategy: random, sparse, dense, clusters or exec:path")
+	b := fs.String("b", "clusters", "second strategy")
+	rounds := fs.Int("rounds", 20, "rounds to play")
+	generations := fs.Int("generations", 500, "generations per round")
+	ruleString := fs.String("rule", "conway", "rule to play under")
+	thumbnails := fs.String("thumbnails", "", "directory to save a picture of each round's final board in, as round-001.png and so on")
+	fs.Int64Var(&seed, "seed", 1, "random seed, so a tournament can be replayed")
+	fs.IntVar(&columns, "cols", 64, "board width in cells")
+	fs.IntVar(&rows, "rows", 64, "board height in cells")
+	fs.Var(&boundary, "boundary", "what's past the edge of the board: wrap, dead or mirror")
+	fs.Parse(args)
+
+	if *thumbnails != "" {
+		if err := os.MkdirAll(*thumbnails, 0755); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	if *rounds <= 0 || *generations <= 0 || columns < 2 || rows <= 0 {
+		log.Fatalln("--rounds, --generations and --rows must be positive, and --cols at least 2")
+	}
+	r, err := life.LookupRule(*ruleString)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	setRule(r)
+
+	sides := [2]strategy{}
+	for i, name := range []string{*a, *b} {
+		if sides[i], err = lookupStrategy(name, string("ab"[i])); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	cells := make([][]*cell, columns)
+	for x := range cells {
+		for y := 0; y < rows; y++ {
+			cells[x] = append(cells[x], &cell{x: x, y: y})
+		}
+	}
+	arena := &board{cells: cells, engine: &packedEngine{}}
+
+	rng := rand.New(rand.NewSource(seed))
+	var wins [2]int
+	fmt.Fprintf(w, "a = %v, b = %v, rule %v, %d generations a round, seed %d\n", *a, *b, r, *generations, seed)
+	for round := 0; round < *rounds; round++ {
+		// left is the side seeding the left half this round.
+		left := round % 2
+
+		arena.clear()
+		sides[left](cells, 0, columns/2, rng)
+		sides[1-left](cells, columns/2, columns, rng)
+
+		meta := newChannels(cells, channelOwner)
+		for x := range cells {
+			for y, c := range cells[x] {
+				if c.alive() {
+					side := left
+					if x >= columns/2 {
+						side = 1 - left
+					}
+					meta.owner[meta.index(x, y)] = uint8(side + 1)
+				}
+			}
+		}
+
+		for g := 0; g < *generations; g++ {
+			arena.Step()
+			meta.update(cells)
+		}
+
+		var held [2]int
+		for x := range cells {
+			for y, c := range cells[x] {
+				if owner := meta.owner[meta.index(x, y)]; c.alive() && owner > 0 {
+					held[owner-1]++
+				}
+			}
+		}
+
+		winner := "draw"
+		switch {
+		case held[0] > held[1]:
+			wins[0]++
+			winner = "a"
+		case held[1] > held[0]:
+			wins[1]++
+			winner = "b"
+		}
+		fmt.Fprintf(w, "round %3d: a %5d  b %5d  %v\n", round+1, held[0], held[1], winner)
+
+		if *thumbnails != "" {
+			path := filepath.Join(*thumbnails, fmt.Sprintf("round-%03d.png", round+1))
+			if err := savePNG(path, arena.Thumbnail(tournamentThumbnailSize, tournamentThumbnailSize)); err != nil {
+				log.Fatalln(err)
+			}
+		}
+	}
+
+	draws := *rounds - wins[0] - wins[1]
+	fmt.Fprintf(w, "a (%v) won %d (%.0f%%), b (%v) won %d (%.0f%%), %d drawn\n",
+		*a, wins[0], 100*float64(wins[0])/float64(*rounds),
+		*b, wins[1], 100*float64(wins[1])/float64(*rounds),
+		draws)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// trackedPhases is how many generations a tracker remembers, which is
+	// the longest period it can measure.
+	trackedPhases = 64
+
+	// trackedCells is the most cells an object can have before the tracker
+	// gives up on it, so picking something that has filled the board doesn't
+	// walk the whole board every generation.
+	trackedCells = 500
+)
+
+// tracker follows one object on the board from generation to generation,
+// finding the period after which it has the same shape again and how far it
+// has moved by then, which gives its velocity. An object is live cells
+// within two cells of each other, so the gaps in a glider's phases don't
+// split it.
+//
+// Under the wrap boundary, cells are kept on the unbounded plane rather than
+// the board, so an object crossing the edge keeps moving the same way
+// instead of jumping back across the board.
+type tracker struct {
+	cells  []point
+	phases []trackedPhase
+}
+
+// trackedPhase is what the tracked object looked like in one generation.
+type trackedPhase struct {
+	shape  string
+	corner point
+}
+
+// start begins tracking the object with a live cell at or next to x, y, and
+// reports whether there was one.
+func (t *tracker) start(cells [][]*cell, x, y int) bool {
+	t.cells, t.phases = nil, nil
+	t.find(cells, []point{{x, y}})
+	return t.cells != nil
+}
+
+// stop forgets the object, as after the board jumps to another generation.
+func (t *tracker) stop() {
+	t.cells, t.phases = nil, nil
+}
+
+// tracking reports whether there's an object being followed.
+func (t *tracker) tracking() bool {
+	return t.cells != nil
+}
+
+// observe finds the object again in the next generation, around where it
+// was, and stops tracking if it has died out or grown too big.
+func (t *tracker) observe(cells [][]*cell) {
+	if t.cells == nil {
+		return
+	}
+	t.find(cells, t.cells)
+}
+
+// find replaces the object with the live cells reached from those at or next
+// to from, and records its phase.
+func (t *tracker) find(cells [][]*cell, from []point) {
+	found := reach(cells, from, map[point]bool{}, trackedCells)
+	if len(found) == 0 || len(found) > trackedCells {
+		t.stop()
+		return
+	}
+
+	t.cells = found
+	t.phases = append(t.phases, shapeOf(found))
+	if len(t.phases) > trackedPhases {
+		t.phases = t.phases[1:]
+	}
+}
+
+// reach returns the object made of the live cells at or next to from, and
+// those within two cells of them, as points on the plane. It marks the board
+// cells it finds in seen, and gives up once it has found more than limit.
+func reach(cells [][]*cell, from []point, seen map[point]bool, limit int) []point {
+	var found []point
+	visit := func(p point, reach int) {
+		for dx := -reach; dx <= reach; dx++ {
+			for dy := -reach; dy <= reach; dy++ {
+				q := point{p.x + dx, p.y + dy}
+				x, y, ok := onBoard(q)
+				if !ok || seen[point{x, y}] || !cells[x][y].alive() {
+					continue
+				}
+				seen[point{x, y}] = true
+				found = append(found, q)
+			}
+		}
+	}
+	for _, p := range from {
+		visit(p, 1)
+	}
+	for i := 0; i < len(found) && len(found) <= limit; i++ {
+		visit(found[i], 2)
+	}
+	return found
+}
+
+// onBoard returns the cell of the board at p on the plane, or false if p is
+// off a board that doesn't wrap.
+func onBoard(p point) (int, int, bool) {
+	if boundary == boundaryWrap {
+		return (p.x%columns + columns) % columns, (p.y%rows + rows) % rows, true
+	}
+	if p.x < 0 || p.x >= columns || p.y < 0 || p.y >= rows {
+		return 0, 0, false
+	}
+	return p.x, p.y, true
+}
+
+// shapeOf returns cells as a shape, the same wherever it is on the board,
+// along with the corner of its bounding box.
+func shapeOf(cells []point) trackedPhase {
+	corner := cells[0]
+	for _, c := range cells {
+		if c.x < corner.x {
+			corner.x = c.x
+		}
+		if c.y < corner.y {
+			corner.y = c.y
+		}
+	}
+	offsets := make([]string, len(cells))
+	for i, c := range cells {
+		offsets[i] = fmt.Sprintf("%v,%v", c.x-corner.x, c.y-corner.y)
+	}
+	sort.Strings(offsets)
+	return trackedPhase{shape: strings.Join(offsets, " "), corner: corner}
+}
+
+// motion returns the shortest period after which the object has had the
+// same shape, and how far it moved over it, or false if it hasn't repeated
+// within the generations remembered.
+func (t *tracker) motion() (period, dx, dy int, ok bool) {
+	last := len(t.phases) - 1
+	for p := 1; p <= last; p++ {
+		if before := t.phases[last-p]; before.shape == t.phases[last].shape {
+			return p, t.phases[last].corner.x - before.corner.x, t.phases[last].corner.y - before.corner.y, true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// summary describes the object's motion for the HUD, such as "c/4
+// diagonal, moving 1,-1 every 4 generations" for a glider.
+func (t *tracker) summary() string {
+	period, dx, dy, ok := t.motion()
+	switch {
+	case !ok:
+		return tr("Tracking: no period yet")
+	case dx == 0 && dy == 0 && period == 1:
+		return tr("Tracking: still life")
+	case dx == 0 && dy == 0:
+		return fmt.Sprintf(tr("Tracking: period %v oscillator"), period)
+	}
+
+	ax, ay := dx, dy
+	if ax < 0 {
+		ax = -ax
+	}
+	if ay < 0 {
+		ay = -ay
+	}
+	var direction string
+	switch {
+	case ax == 0 || ay == 0:
+		direction = fmt.Sprintf(tr("%v orthogonal"), speed(ax+ay, period))
+	case ax == ay:
+		direction = fmt.Sprintf(tr("%v diagonal"), speed(ax, period))
+	default:
+		// Oblique speeds are written with the whole displacement, unreduced.
+		direction = fmt.Sprintf(tr("(%v,%v)c/%v oblique"), ax, ay, period)
+	}
+	return fmt.Sprintf(tr("Tracking: %v, moving %v,%v every %v generations"), direction, dx, dy, period)
+}
+
+// speed writes cells cells per period generations as a fraction of the speed
+// of light, c, in lowest terms: 2c/4 is c/2.
+func speed(cells, period int) string {
+	a, b := cells, period
+	for b != 0 {
+		a, b = b, a%b
+	}
+	cells, period = cells/a, period/a
+
+	s := "c"
+	if cells != 1 {
+		s = fmt.Sprint(cells, "c")
+	}
+	if period != 1 {
+		s += fmt.Sprint("/", period)
+	}
+	return s
+}
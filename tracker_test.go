@@ -0,0 +1,123 @@
+package main
+
+import "testing"
+
+// TestTracker follows objects across the edge of a wrapping board and checks
+// the velocities it gives them.
+func TestTracker(t *testing.T) {
+	savedBoundary := boundary
+	t.Cleanup(func() { boundary = savedBoundary })
+	boundary = boundaryWrap
+
+	for _, c := range []struct {
+		name  string
+		board []string
+		want  string
+	}{
+		{"glider", []string{
+			"..........",
+			"...O......",
+			"....O.....",
+			"..OOO.....",
+			"..........",
+			"..........",
+			"..........",
+			"..........",
+		}, "Tracking: c/4 diagonal, moving 1,-1 every 4 generations"},
+		{"lightweight spaceship", []string{
+			"............",
+			"............",
+			".O..O.......",
+			".....O......",
+			".O...O......",
+			"..OOOO......",
+			"............",
+			"............",
+			"............",
+		}, "Tracking: c/2 orthogonal, moving 2,0 every 4 generations"},
+		{"blinker", []string{
+			".....",
+			".....",
+			".OOO.",
+			".....",
+			".....",
+		}, "Tracking: period 2 oscillator"},
+		{"block", []string{
+			"....",
+			".OO.",
+			".OO.",
+			"....",
+		}, "Tracking: still life"},
+	} {
+		useRule(t, "B3/S23", len(c.board[0]), len(c.board))
+
+		b := &board{cells: renderCells(c.board), engine: naiveEngine{}}
+		var track tracker
+		at := point{}
+		for x := range b.cells {
+			for y := range b.cells[x] {
+				if b.cells[x][y].alive() {
+					at = point{x, y}
+				}
+			}
+		}
+		if !track.start(b.cells, at.x, at.y) {
+			t.Fatalf("%v: nothing to track at %v", c.name, at)
+		}
+		if got := track.summary(); got != "Tracking: no period yet" {
+			t.Errorf("%v: a period was found from one generation: %v", c.name, got)
+		}
+		// Long enough to cross the board and its edge.
+		for i := 0; i < 4*len(c.board[0]); i++ {
+			b.Step()
+			track.observe(b.cells)
+		}
+		if got := track.summary(); got != c.want {
+			t.Errorf("%v: %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+// TestTrackerLosesObject checks that tracking starts only next to a live
+// cell, and stops once the object dies out.
+func TestTrackerLosesObject(t *testing.T) {
+	useRule(t, "B3/S23", 6, 6)
+	b := &board{cells: renderCells([]string{
+		"......",
+		"......",
+		"..O...",
+		"......",
+		"......",
+		"......",
+	}), engine: naiveEngine{}}
+	var track tracker
+	if track.start(b.cells, 0, 0) {
+		t.Error("tracking started away from any live cell")
+	}
+	if !track.start(b.cells, 3, 3) {
+		t.Fatal("the lone cell next to the cursor wasn't picked")
+	}
+	b.Step()
+	track.observe(b.cells)
+	if track.tracking() {
+		t.Error("still tracking after the object died")
+	}
+}
+
+// TestSpeed checks that speeds are written in lowest terms.
+func TestSpeed(t *testing.T) {
+	for _, c := range []struct {
+		cells, period int
+		want          string
+	}{
+		{1, 4, "c/4"},
+		{2, 4, "c/2"},
+		{1, 1, "c"},
+		{2, 5, "2c/5"},
+		{4, 2, "2c"},
+	} {
+		if got := speed(c.cells, c.period); got != c.want {
+			t.Errorf("speed(%v, %v) = %v, want %v", c.cells, c.period, got, c.want)
+		}
+	}
+}
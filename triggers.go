@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+)
+
+// trigger fires its actions when the board meets all of its conditions, as
+// read from the --triggers file, a JSON list such as
+//
+//	[{"name": "boom", "population_above": 5000, "actions": ["screenshot", "pause"]},
+//	 {"generation": 1000, "actions": ["save"]},
+//	 {"stagnant": true, "run": "./notify.sh", "webhook": "http://localhost:8080/life"}]
+//
+// A trigger fires once when its conditions become true, and can only fire
+// again after they have stopped being true, so a population that stays high
+// doesn't take a screenshot every generation.
+type trigger struct {
+	Name            string `json:"name"`
+	PopulationAbove *int   `json:"population_above"`
+	PopulationBelow *int   `json:"population_below"`
+	Generation      *int   `json:"generation"` // reached or passed, since warps skip generations
+	Stagnant        bool   `json:"stagnant"`
+
+	// Actions are any of screenshot, save and pause. Run is a shell command
+	// run with the board as RLE on its stdin, and Webhook a URL the event is
+	// POSTed to.
+	Actions []string `json:"actions"`
+	Run     string   `json:"run"`
+	Webhook string   `json:"webhook"`
+
+	notify *notifier
+	met    bool
+}
+
+var triggerActions = []string{"screenshot", "save", "pause"}
+
+// loadTriggers reads and checks the triggers saved as JSON at path.
+func loadTriggers(path string) ([]*trigger, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*trigger
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	for i, t := range list {
+		if t.Name == "" {
+			t.Name = fmt.Sprintf("trigger %d", i+1)
+		}
+		if err := t.check(); err != nil {
+			return nil, fmt.Errorf("reading %v: %v: %v", path, t.Name, err)
+		}
+		t.notify = newNotifier(t.Webhook)
+	}
+	return list, nil
+}
+
+// check returns an error if the trigger has no condition or nothing to do.
+func (t *trigger) check() error {
+	if t.PopulationAbove == nil && t.PopulationBelow == nil && t.Generation == nil && !t.Stagnant {
+		return fmt.Errorf("needs a condition: population_above, population_below, generation or stagnant")
+	}
+	if len(t.Actions) == 0 && t.Run == "" && t.Webhook == "" {
+		return fmt.Errorf("needs actions, run or webhook")
+	}
+	for _, a := range t.Actions {
+		if !containsString(triggerActions, a) {
+			return fmt.Errorf("unknown action %q, want screenshot, save or pause", a)
+		}
+	}
+	return nil
+}
+
+// has reports whether action is one of the trigger's actions.
+func (t *trigger) has(action string) bool {
+	return containsString(t.Actions, action)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// meets reports whether the board the watcher last saw meets every condition.
+func (t *trigger) meets(w *watcher) bool {
+	if t.PopulationAbove != nil && w.population <= *t.PopulationAbove {
+		return false
+	}
+	if t.PopulationBelow != nil && w.population >= *t.PopulationBelow {
+		return false
+	}
+	if t.Generation != nil && w.generation < *t.Generation {
+		return false
+	}
+	if t.Stagnant && !w.stagnant {
+		return false
+	}
+	return true
+}
+
+// checkTriggers returns the triggers that fire on the board the watcher last
+// saw, having run their shell commands and sent their webhooks. The caller
+// must hold the simulation lock, and carries out the rest of their actions.
+func checkTriggers(triggers []*trigger, w *watcher, cells [][]*cell) []*trigger {
+	var fired []*trigger
+	for _, t := range triggers {
+		met := t.meets(w)
+		if met && !t.met {
+			fired = append(fired, t)
+			log.Printf("Trigger %q fired at generation %v", t.Name, w.generation)
+			t.notify.emit(t.Name, w.generation, w.population)
+			if t.Run != "" {
+				t.run(cells, w.generation, w.population)
+			}
+		}
+		t.met = met
+	}
+	return fired
+}
+
+// run starts the trigger's shell command in the background, with the board
+// on its stdin as RLE and the same environment as --on-generation-script.
+func (t *trigger) run(cells [][]*cell, generation, population int) {
+	var rle bytes.Buffer
+	if err := encodeRLE(&rle, snapshot(cells), ruleName()); err != nil {
+		log.Println("encoding board for trigger:", err)
+		return
+	}
+
+	cmd := exec.Command("sh", "-c", t.Run)
+	cmd.Stdin = &rle
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("GOL_TRIGGER=%v", t.Name),
+		fmt.Sprintf("GOL_GENERATION=%d", generation),
+		fmt.Sprintf("GOL_POPULATION=%d", population),
+		fmt.Sprintf("GOL_RULE=%v", ruleName()),
+		fmt.Sprintf("GOL_WIDTH=%d", len(cells)),
+		fmt.Sprintf("GOL_HEIGHT=%d", len(cells[0])),
+	)
+
+	go func() {
+		if err := cmd.Run(); err != nil {
+			log.Printf("running trigger %q: %v", t.Name, err)
+		}
+	}()
+}
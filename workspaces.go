@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/jake-shasteen/golang-gl-conway-life/input"
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// savedWorkspace is every tab, along with the HUD settings, saved under a
+// name to open again later with --workspace or from the palette. History
+// isn't kept, only each tab's board, starting board and bookmarks.
+type savedWorkspace struct {
+	Columns int        `json:"columns"`
+	Rows    int        `json:"rows"`
+	Current int        `json:"current"`
+	HUD     bool       `json:"hud"`
+	Perf    bool       `json:"perf"`
+	Grid    bool       `json:"grid"`
+	Tabs    []savedTab `json:"tabs"`
+}
+
+// savedTab is a tab as kept in a workspace file. Cells and Initial hold
+// states as returned by board.Save.
+type savedTab struct {
+	Rule       string          `json:"rule"`
+	LtL        string          `json:"ltl,omitempty"`
+	Boundary   string          `json:"boundary"`
+	Generation int             `json:"generation"`
+	Paused     bool            `json:"paused"`
+	Camera     camera          `json:"camera"`
+	Cells      []uint8         `json:"cells"`
+	Initial    []uint8         `json:"initial"`
+	Bookmarks  []savedBookmark `json:"bookmarks"`
+}
+
+type savedBookmark struct {
+	Generation int     `json:"generation"`
+	Population int     `json:"population"`
+	Cells      []uint8 `json:"cells"`
+	Thumbnail  string  `json:"thumbnail,omitempty"`
+}
+
+// workspacesDir returns where workspaces are kept, such as
+// ~/.config/gol/workspaces on Linux.
+func workspacesDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gol", "workspaces"), nil
+}
+
+// workspacePath returns the file the workspace called name is kept in.
+func workspacePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("%q can't be a workspace's name", name)
+	}
+	dir, err := workspacesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// workspaceNames returns the names of the saved workspaces, in order.
+func workspaceNames() []string {
+	dir, err := workspacesDir()
+	if err != nil {
+		return nil
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	var names []string
+	for _, f := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(f), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadWorkspace reads the workspace called name.
+func loadWorkspace(name string) (*savedWorkspace, error) {
+	path, err := workspacePath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no workspace %q; try %v", name, strings.Join(workspaceNames(), ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var w savedWorkspace
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("reading %v: %v", path, err)
+	}
+	if w.Columns <= 0 || w.Rows <= 0 || len(w.Tabs) == 0 || w.Current < 0 || w.Current >= len(w.Tabs) {
+		return nil, fmt.Errorf("reading %v: the workspace is damaged", path)
+	}
+	size := w.Columns * w.Rows
+	for _, t := range w.Tabs {
+		damaged := len(t.Cells) != size || len(t.Initial) != size || t.Camera.Zoom <= 0
+		for _, m := range t.Bookmarks {
+			damaged = damaged || len(m.Cells) != size
+		}
+		if damaged {
+			return nil, fmt.Errorf("reading %v: the workspace is damaged", path)
+		}
+	}
+	return &w, nil
+}
+
+// saveWorkspace writes w as the workspace called name, replacing any saved
+// under that name before.
+func saveWorkspace(name string, w *savedWorkspace) error {
+	path, err := workspacePath(name)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// saveTab returns t as it's kept in a workspace file.
+func saveTab(t *tab) savedTab {
+	s := savedTab{
+		Rule:       t.rule.String(),
+		Boundary:   t.boundary.String(),
+		Generation: t.watch.generation,
+		Paused:     t.paused,
+		Camera:     t.view,
+		Cells:      t.state,
+	}
+	if t.ltl != nil {
+		s.LtL = t.ltl.String()
+	}
+	for x := range t.initial {
+		for _, alive := range t.initial[x] {
+			state := uint8(dead)
+			if alive {
+				state = live
+			}
+			s.Initial = append(s.Initial, state)
+		}
+	}
+	for _, m := range t.marks.marks {
+		s.Bookmarks = append(s.Bookmarks, savedBookmark{m.generation, m.population, m.state, m.path})
+	}
+	return s
+}
+
+// tab returns s as a tab on a columns by rows board, with its own history
+// of historySize generations.
+func (s savedTab) tab(columns, rows, historySize int) (*tab, error) {
+	t := &tab{
+		state:  s.Cells,
+		watch:  watcher{generation: s.Generation},
+		paused: s.Paused,
+		view:   s.Camera,
+		past:   newHistory(historySize),
+		marks:  &bookmarks{},
+	}
+	var err error
+	if t.rule, err = life.LookupRule(s.Rule); err != nil {
+		return nil, err
+	}
+	if s.LtL != "" {
+		if t.ltl, err = life.ParseLtL(s.LtL); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.boundary.Set(s.Boundary); err != nil {
+		return nil, err
+	}
+
+	t.initial = make([][]bool, columns)
+	for x := range t.initial {
+		t.initial[x] = make([]bool, rows)
+		for y := range t.initial[x] {
+			t.initial[x][y] = s.Initial[x*rows+y] == live
+		}
+	}
+	for _, m := range s.Bookmarks {
+		t.marks.marks = append(t.marks.marks, bookmark{generation: m.Generation, population: m.Population, state: m.Cells, path: m.Thumbnail})
+	}
+	return t, nil
+}
+
+// namePrompt asks for a workspace's name in the window title, as the
+// palette does for commands.
+type namePrompt struct {
+	format string // the title, taking the name and the saved workspaces
+	name   string
+}
+
+// handle applies one piece of typed input, and reports whether the prompt is
+// still open and whether the name was entered.
+func (p *namePrompt) handle(t input.Text) (open, entered bool) {
+	switch {
+	case t.Key == glfw.KeyEscape:
+		return false, false
+	case t.Key == glfw.KeyEnter || t.Key == glfw.KeyKPEnter:
+		return false, p.name != ""
+	case t.Key == glfw.KeyBackspace:
+		if r := []rune(p.name); len(r) > 0 {
+			p.name = string(r[:len(r)-1])
+		}
+	case t.Rune != 0:
+		p.name += string(t.Rune)
+	}
+	return true, false
+}
+
+// title describes the prompt's state for the window title, listing the
+// workspaces already saved.
+func (p *namePrompt) title() string {
+	saved := strings.Join(workspaceNames(), ", ")
+	if saved == "" {
+		saved = "-"
+	}
+	return fmt.Sprintf(tr(p.format), p.name, saved)
+}
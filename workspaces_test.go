@@ -0,0 +1,43 @@
+package main
+
+import (
+	"github.com/jake-shasteen/golang-gl-conway-life/life"
+	"reflect"
+	"testing"
+)
+
+// TestSavedTab checks that a tab comes back from a workspace file as it was
+// saved, apart from its history.
+func TestSavedTab(t *testing.T) {
+	highlife, err := life.LookupRule("highlife")
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := [][]bool{{true, false}, {false, false}, {false, true}}
+	want := &tab{
+		state:    []uint8{live, dead, dead, live, live, dead},
+		initial:  initial,
+		watch:    watcher{generation: 42},
+		paused:   true,
+		rule:     highlife,
+		boundary: boundaryWrap,
+		view:     camera{X: 1, Y: 2, Zoom: 4},
+		marks:    &bookmarks{marks: []bookmark{{generation: 7, population: 3, state: []uint8{live, live, live, dead, dead, dead}}}},
+	}
+
+	got, err := saveTab(want).tab(3, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.past == nil {
+		t.Error("the tab came back without a history")
+	}
+	got.past, want.past = nil, nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := workspacePath("../elsewhere"); err == nil {
+		t.Error("a workspace name was accepted that leaves the workspaces directory")
+	}
+}